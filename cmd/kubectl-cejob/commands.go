@@ -0,0 +1,472 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/builder"
+	typedv1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/typed/codeengine/v1beta1"
+)
+
+const (
+	kindJobRun        = "jobrun"
+	kindJobDefinition = "jobdefinition"
+)
+
+// parseKind resolves the singular, plural and short names of the supported kinds.
+func parseKind(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "jobrun", "jobruns", "jr":
+		return kindJobRun, nil
+	case "jobdefinition", "jobdefinitions", "jd":
+		return kindJobDefinition, nil
+	}
+	return "", fmt.Errorf("unknown kind %q, expected jobrun or jobdefinition", s)
+}
+
+func getObject(ctx context.Context, o *options, kind, name string) (runtime.Object, error) {
+	if kind == kindJobRun {
+		return o.client.CodeengineV1beta1().JobRuns(o.namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	return o.client.CodeengineV1beta1().JobDefinitions(o.namespace).Get(ctx, name, metav1.GetOptions{})
+}
+
+func listObjects(ctx context.Context, o *options, kind string) (runtime.Object, error) {
+	if kind == kindJobRun {
+		return o.client.CodeengineV1beta1().JobRuns(o.namespace).List(ctx, metav1.ListOptions{})
+	}
+	return o.client.CodeengineV1beta1().JobDefinitions(o.namespace).List(ctx, metav1.ListOptions{})
+}
+
+// submitCommand creates a job run from a manifest or from flags.
+type submitCommand struct {
+	file             string
+	name             string
+	jobDefinition    string
+	image            string
+	command          []string
+	args             []string
+	env              []string
+	arraySpec        string
+	retryLimit       int64
+	maxExecutionTime int64
+	parallelism      int64
+	daemon           bool
+	priority         int32
+	wait             bool
+	timeout          time.Duration
+}
+
+func (c *submitCommand) usage() (string, string) {
+	return "", "Submit a job run from a file (-f) or from flags"
+}
+
+func (c *submitCommand) addFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&c.file, "filename", "f", "", "File with the JobRun manifest in YAML or JSON, - for stdin")
+	fs.StringVar(&c.name, "name", "", "Name of the job run, generated from the job definition if not set")
+	fs.StringVar(&c.jobDefinition, "job-definition", "", "Name of the job definition to refer")
+	fs.StringVar(&c.image, "image", "", "Image of the job run container")
+	fs.StringArrayVar(&c.command, "command", nil, "Command of the job run container, repeat for each element")
+	fs.StringArrayVar(&c.args, "arg", nil, "Arguments of the job run container, repeat for each argument")
+	fs.StringArrayVarP(&c.env, "env", "e", nil, "Environment variable NAME=VALUE, repeat for each variable")
+	fs.StringVar(&c.arraySpec, "array-spec", "", "Indices of the job run, e.g. 0-9,20")
+	fs.Int64Var(&c.retryLimit, "retry-limit", -1, "Number of retries of an index before it's marked failed")
+	fs.Int64Var(&c.maxExecutionTime, "max-execution-time", 0, "Maximum execution time in seconds")
+	fs.Int64Var(&c.parallelism, "parallelism", 0, "Maximum number of indices running at the same time")
+	fs.BoolVar(&c.daemon, "daemon", false, "Run the pods in daemon mode, until the job run is deleted")
+	fs.Int32Var(&c.priority, "priority", 0, "Priority of the job run, higher values are submitted first by queues")
+	fs.BoolVarP(&c.wait, "wait", "w", false, "Wait for the job run to finish")
+	fs.DurationVar(&c.timeout, "timeout", 0, "Maximum time to wait with --wait, e.g. 30m, no limit if not set")
+}
+
+func (c *submitCommand) run(ctx context.Context, o *options, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("unexpected arguments %v", args)
+	}
+
+	jr, err := c.jobRun(o.namespace)
+	if err != nil {
+		return err
+	}
+
+	created, err := o.client.CodeengineV1beta1().JobRuns(o.namespace).Create(ctx, jr, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	if !c.wait {
+		return printObject(o.out, o.output, created)
+	}
+	return waitForJobRun(ctx, o, created.Name, c.timeout)
+}
+
+// jobRun reads the job run from the manifest, applies the flags on top of it and validates the result.
+func (c *submitCommand) jobRun(namespace string) (*v1beta1.JobRun, error) {
+	jr := &v1beta1.JobRun{}
+	if c.file != "" {
+		var data []byte
+		var err error
+		if c.file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(c.file)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.UnmarshalStrict(data, jr); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %w", c.file, err)
+		}
+	}
+
+	if c.name != "" {
+		jr.Name = c.name
+	}
+	jobDefinition := jr.Spec.JobDefinitionRef
+	if c.jobDefinition != "" {
+		jobDefinition = c.jobDefinition
+	}
+	if jr.Name == "" && jr.GenerateName == "" && jobDefinition == "" {
+		return nil, fmt.Errorf("either --name or --job-definition must be set")
+	}
+
+	b := builder.ForJobRun(jr).Namespace(namespace)
+	if c.jobDefinition != "" {
+		b.FromDefinition(c.jobDefinition)
+	}
+	if jr.Name == "" && jr.GenerateName == "" {
+		b.GenerateName(jobDefinition + "-")
+	}
+	if c.arraySpec != "" {
+		b.Array(c.arraySpec)
+	}
+	if c.retryLimit >= 0 {
+		b.Retries(c.retryLimit)
+	}
+	if c.maxExecutionTime > 0 {
+		b.Timeout(time.Duration(c.maxExecutionTime) * time.Second)
+	}
+	if c.parallelism > 0 {
+		b.Parallelism(c.parallelism)
+	}
+	if c.daemon {
+		b.Daemon()
+	}
+	if c.priority != 0 {
+		b.Priority(c.priority)
+	}
+	if c.image != "" {
+		b.Image(c.image)
+	}
+	if len(c.command) > 0 {
+		b.Command(c.command...)
+	}
+	if len(c.args) > 0 {
+		b.Args(c.args...)
+	}
+	for _, env := range c.env {
+		name, value, ok := strings.Cut(env, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid environment variable %q, expected NAME=VALUE", env)
+		}
+		b.Env(name, value)
+	}
+	return b.Build()
+}
+
+// getCommand displays one or many resources.
+type getCommand struct{}
+
+func (c *getCommand) usage() (string, string) {
+	return "<kind> [name...]", "Display one or many resources"
+}
+
+func (c *getCommand) addFlags(*pflag.FlagSet) {}
+
+func (c *getCommand) run(ctx context.Context, o *options, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing kind")
+	}
+	kind, err := parseKind(args[0])
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		return (&listCommand{}).run(ctx, o, args)
+	}
+
+	for _, name := range args[1:] {
+		obj, err := getObject(ctx, o, kind, name)
+		if err != nil {
+			return err
+		}
+		if err := printObject(o.out, o.output, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listCommand displays all resources of a kind.
+type listCommand struct{}
+
+func (c *listCommand) usage() (string, string) {
+	return "<kind>", "Display all resources of a kind"
+}
+
+func (c *listCommand) addFlags(*pflag.FlagSet) {}
+
+func (c *listCommand) run(ctx context.Context, o *options, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one kind")
+	}
+	kind, err := parseKind(args[0])
+	if err != nil {
+		return err
+	}
+
+	obj, err := listObjects(ctx, o, kind)
+	if err != nil {
+		return err
+	}
+	return printObject(o.out, o.output, obj)
+}
+
+// describeCommand shows details of a resource.
+type describeCommand struct{}
+
+func (c *describeCommand) usage() (string, string) {
+	return "<kind> <name>", "Show details of a resource"
+}
+
+func (c *describeCommand) addFlags(*pflag.FlagSet) {}
+
+func (c *describeCommand) run(ctx context.Context, o *options, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expected kind and name")
+	}
+	kind, err := parseKind(args[0])
+	if err != nil {
+		return err
+	}
+
+	obj, err := getObject(ctx, o, kind, args[1])
+	if err != nil {
+		return err
+	}
+	if o.output != outputTable {
+		return printObject(o.out, o.output, obj)
+	}
+
+	tw := tabwriter.NewWriter(o.out, 0, 8, 1, ' ', 0)
+	switch obj := obj.(type) {
+	case *v1beta1.JobRun:
+		describeJobRun(tw, obj)
+	case *v1beta1.JobDefinition:
+		describeJobDefinition(tw, obj)
+	}
+	return tw.Flush()
+}
+
+func describeJobRun(w io.Writer, jr *v1beta1.JobRun) {
+	fmt.Fprintf(w, "Name:\t%s\n", jr.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", jr.Namespace)
+	fmt.Fprintf(w, "Job Definition:\t%s\n", valueOrNone(jr.Spec.JobDefinitionRef))
+	fmt.Fprintf(w, "Created:\t%s\n", jr.CreationTimestamp)
+	describeJobDefinitionSpec(w, &jr.Spec.JobDefinitionSpec)
+
+	status := &jr.Status
+	fmt.Fprintf(w, "Status:\t%s\n", jr.Phase())
+	if status.StartTime != nil {
+		fmt.Fprintf(w, "Start Time:\t%s\n", status.StartTime)
+	}
+	if status.CompletionTime != nil {
+		fmt.Fprintf(w, "Completion Time:\t%s\n", status.CompletionTime)
+	}
+	fmt.Fprintf(w, "Instances:\t%d requested / %d throttled / %d pending / %d running / %d succeeded / %d failed / %d unknown\n",
+		status.Requested, status.Throttled, status.Pending, status.Running, status.Succeeded, status.Failed, status.Unknown)
+	fmt.Fprintf(w, "Succeeded Indices:\t%s\n", stringOrNone(status.SucceededIndices))
+	fmt.Fprintf(w, "Failed Indices:\t%s\n", stringOrNone(status.FailedIndices))
+
+	if len(status.Conditions) > 0 {
+		fmt.Fprintln(w, "Conditions:")
+		fmt.Fprintln(w, "  TYPE\tSTATUS\tLAST TRANSITION\tREASON\tMESSAGE")
+		for _, c := range status.Conditions {
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, c.LastTransitionTime, c.Reason, c.Message)
+		}
+	}
+}
+
+func describeJobDefinition(w io.Writer, jd *v1beta1.JobDefinition) {
+	fmt.Fprintf(w, "Name:\t%s\n", jd.Name)
+	fmt.Fprintf(w, "Namespace:\t%s\n", jd.Namespace)
+	fmt.Fprintf(w, "Created:\t%s\n", jd.CreationTimestamp)
+	describeJobDefinitionSpec(w, &jd.Spec)
+	if jd.Status.Address != nil && jd.Status.Address.URL != nil {
+		fmt.Fprintf(w, "Address:\t%s\n", jd.Status.Address.URL)
+	}
+}
+
+func describeJobDefinitionSpec(w io.Writer, jds *v1beta1.JobDefinitionSpec) {
+	fmt.Fprintf(w, "Array Spec:\t%s\n", stringOrNone(jds.ArraySpec))
+	fmt.Fprintf(w, "Retry Limit:\t%s\n", int64OrNone(jds.RetryLimit))
+	fmt.Fprintf(w, "Max Execution Time:\t%s\n", int64OrNone(jds.MaxExecutionTime))
+	fmt.Fprintf(w, "Parallelism:\t%s\n", int64OrNone(jds.Parallelism))
+	fmt.Fprintf(w, "Execution Mode:\t%s\n", jds.GetExecutionMode())
+	if jds.Template.ServiceAccountName != "" {
+		fmt.Fprintf(w, "Service Account:\t%s\n", jds.Template.ServiceAccountName)
+	}
+	for _, v := range jds.Template.Volumes {
+		fmt.Fprintf(w, "Volume %s:\t%s\n", v.Name, volumeSource(&v))
+	}
+	for i := range jds.Template.InitContainers {
+		describeContainer(w, "Init Container", &jds.Template.InitContainers[i])
+	}
+	main := jds.Template.GetMainContainer()
+	for i := range jds.Template.Containers {
+		c := &jds.Template.Containers[i]
+		if c == main {
+			describeContainer(w, "Main Container", c)
+		} else {
+			describeContainer(w, "Sidecar", c)
+		}
+	}
+}
+
+func describeContainer(w io.Writer, title string, c *corev1.Container) {
+	fmt.Fprintf(w, "%s %s:\n", title, valueOrNone(c.Name))
+	fmt.Fprintf(w, "  Image:\t%s\n", valueOrNone(c.Image))
+	if len(c.Command) > 0 {
+		fmt.Fprintf(w, "  Command:\t%s\n", strings.Join(c.Command, " "))
+	}
+	if len(c.Args) > 0 {
+		fmt.Fprintf(w, "  Args:\t%s\n", strings.Join(c.Args, " "))
+	}
+	for _, env := range c.Env {
+		fmt.Fprintf(w, "  Env %s:\t%s\n", env.Name, env.Value)
+	}
+	for _, m := range c.VolumeMounts {
+		fmt.Fprintf(w, "  Mount %s:\t%s\n", m.Name, m.MountPath)
+	}
+}
+
+// volumeSource describes the source of a volume.
+func volumeSource(v *corev1.Volume) string {
+	switch {
+	case v.Secret != nil:
+		return "secret " + v.Secret.SecretName
+	case v.ConfigMap != nil:
+		return "configMap " + v.ConfigMap.Name
+	case v.EmptyDir != nil:
+		return "emptyDir"
+	case v.PersistentVolumeClaim != nil:
+		return "persistentVolumeClaim " + v.PersistentVolumeClaim.ClaimName
+	}
+	return "<unsupported>"
+}
+
+// watchCommand waits for a job run to finish.
+type watchCommand struct {
+	timeout time.Duration
+}
+
+func (c *watchCommand) usage() (string, string) {
+	return "<name>", "Wait for a job run to finish, reporting its progress"
+}
+
+func (c *watchCommand) addFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&c.timeout, "timeout", 0, "Maximum time to wait, e.g. 30m, no limit if not set")
+}
+
+func (c *watchCommand) run(ctx context.Context, o *options, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected exactly one job run name")
+	}
+	return waitForJobRun(ctx, o, args[0], c.timeout)
+}
+
+// waitForJobRun reports the progress of the job run until it finishes.
+// An error is returned if the job run failed or didn't finish within the timeout, if set.
+func waitForJobRun(ctx context.Context, o *options, name string, timeout time.Duration) error {
+	res, err := o.client.CodeengineV1beta1().JobRuns(o.namespace).WaitForCompletion(ctx, name, typedv1beta1.WaitOptions{
+		Timeout: timeout,
+		OnProgress: func(jr *v1beta1.JobRun) {
+			s := &jr.Status
+			fmt.Fprintf(o.errOut, "%s %s: %d requested, %d throttled, %d pending, %d running, %d succeeded, %d failed\n",
+				time.Now().Format(time.RFC3339), jr.Phase(), s.Requested, s.Throttled, s.Pending, s.Running, s.Succeeded, s.Failed)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	switch res.Outcome {
+	case typedv1beta1.WaitOutcomeTimeout:
+		return fmt.Errorf("timed out waiting for job run %q", name)
+	case typedv1beta1.WaitOutcomeFailed:
+		if err := printObject(o.out, o.output, res.JobRun); err != nil {
+			return err
+		}
+		return fmt.Errorf("job run %q failed, failed indices: %s", name, res.FailedIndices)
+	}
+	return printObject(o.out, o.output, res.JobRun)
+}
+
+// deleteCommand deletes resources.
+type deleteCommand struct{}
+
+func (c *deleteCommand) usage() (string, string) {
+	return "<kind> <name...>", "Delete resources"
+}
+
+func (c *deleteCommand) addFlags(*pflag.FlagSet) {}
+
+func (c *deleteCommand) run(ctx context.Context, o *options, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("expected kind and at least one name")
+	}
+	kind, err := parseKind(args[0])
+	if err != nil {
+		return err
+	}
+
+	for _, name := range args[1:] {
+		if kind == kindJobRun {
+			err = o.client.CodeengineV1beta1().JobRuns(o.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		} else {
+			err = o.client.CodeengineV1beta1().JobDefinitions(o.namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s %q deleted\n", kind, name)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/fake"
+)
+
+// runCommand runs the command like run does, with the given client in namespace ns.
+func runCommand(client *fake.Clientset, args ...string) (stdout, stderr string, err error) {
+	cmd := commands[args[0]]()
+	var out, errOut bytes.Buffer
+	o := &options{client: client, out: &out, errOut: &errOut}
+	fs := pflag.NewFlagSet(args[0], pflag.ContinueOnError)
+	o.addFlags(fs)
+	cmd.addFlags(fs)
+	if err := fs.Parse(args[1:]); err != nil {
+		return "", "", err
+	}
+	if o.namespace == "" {
+		o.namespace = "ns"
+	}
+	err = cmd.run(context.Background(), o, fs.Args())
+	return out.String(), errOut.String(), err
+}
+
+func newJobRun(name string, mark func(m *v1beta1.JobRunConditionManager)) *v1beta1.JobRun {
+	jr := &v1beta1.JobRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"},
+		Spec:       v1beta1.JobRunSpec{JobDefinitionRef: "def"},
+	}
+	m := jr.Status.ManageConditions(clock.RealClock{})
+	m.MarkPending()
+	if mark != nil {
+		mark(m)
+	}
+	return jr
+}
+
+func TestParseKind(t *testing.T) {
+	for _, s := range []string{"jobrun", "JobRuns", "jr"} {
+		if kind, err := parseKind(s); err != nil || kind != kindJobRun {
+			t.Errorf("parseKind(%q) = %q, %v, want %q", s, kind, err, kindJobRun)
+		}
+	}
+	for _, s := range []string{"jobdefinition", "jobdefinitions", "JD"} {
+		if kind, err := parseKind(s); err != nil || kind != kindJobDefinition {
+			t.Errorf("parseKind(%q) = %q, %v, want %q", s, kind, err, kindJobDefinition)
+		}
+	}
+	if _, err := parseKind("pod"); err == nil {
+		t.Error("parseKind(pod) succeeded, want an error")
+	}
+}
+
+func TestSubmitFromFlags(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	_, _, err := runCommand(client, "submit", "--name", "run", "--image", "busybox",
+		"--array-spec", "0-9", "--retry-limit", "2", "-e", "GREETING=hello", "--", "ignored")
+	if err == nil {
+		t.Fatal("submit with arguments succeeded, want an error")
+	}
+
+	out, _, err := runCommand(client, "submit", "--name", "run", "--image", "busybox",
+		"--array-spec", "0-9", "--retry-limit", "2", "-e", "GREETING=hello")
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if !strings.Contains(out, "run") {
+		t.Errorf("submit printed %q, want the job run", out)
+	}
+
+	jr, err := client.CodeengineV1beta1().JobRuns("ns").Get(context.Background(), "run", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("the job run wasn't created: %v", err)
+	}
+	spec := jr.Spec.JobDefinitionSpec
+	c := spec.Template.GetMainContainer()
+	if c == nil || c.Image != "busybox" || len(c.Env) != 1 || c.Env[0].Value != "hello" {
+		t.Errorf("the job run has the container %+v", c)
+	}
+	if spec.ArraySpec == nil || *spec.ArraySpec != "0-9" || spec.RetryLimit == nil || *spec.RetryLimit != 2 {
+		t.Errorf("the job run has arraySpec %v and retryLimit %v", spec.ArraySpec, spec.RetryLimit)
+	}
+}
+
+func TestSubmitFromFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "jobrun.yaml")
+	manifest := `apiVersion: codeengine.cloud.ibm.com/v1beta1
+kind: JobRun
+metadata:
+  name: run
+spec:
+  jobDefinitionRef: def
+`
+	if err := os.WriteFile(file, []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset()
+	if _, _, err := runCommand(client, "submit", "-f", file, "--array-spec", "3"); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	jr, err := client.CodeengineV1beta1().JobRuns("ns").Get(context.Background(), "run", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("the job run wasn't created: %v", err)
+	}
+	if jr.Spec.JobDefinitionRef != "def" || jr.Spec.JobDefinitionSpec.ArraySpec == nil || *jr.Spec.JobDefinitionSpec.ArraySpec != "3" {
+		t.Errorf("the job run has the spec %+v", jr.Spec)
+	}
+}
+
+func TestSubmitErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "no name", args: []string{"--image", "busybox"}},
+		{name: "invalid env", args: []string{"--name", "run", "--image", "busybox", "-e", "GREETING"}},
+		{name: "missing file", args: []string{"-f", filepath.Join(t.TempDir(), "missing.yaml")}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+			if _, _, err := runCommand(client, append([]string{"submit"}, test.args...)...); err == nil {
+				t.Error("submit succeeded, want an error")
+			}
+			if len(client.Actions()) != 0 {
+				t.Errorf("submit called the server: %v", client.Actions())
+			}
+		})
+	}
+}
+
+func TestGetListDescribe(t *testing.T) {
+	client := fake.NewSimpleClientset(newJobRun("first", nil), newJobRun("second", nil),
+		&v1beta1.JobDefinition{ObjectMeta: metav1.ObjectMeta{Name: "def", Namespace: "ns"}})
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{args: []string{"get", "jr", "first"}, want: []string{"NAME", "first"}},
+		{args: []string{"get", "jr"}, want: []string{"first", "second"}},
+		{args: []string{"list", "jobdefinitions"}, want: []string{"def"}},
+		{args: []string{"get", "jr", "first", "-o", "json"}, want: []string{`"kind": "JobRun"`, `"name": "first"`}},
+		{args: []string{"get", "jd", "def", "-o", "yaml"}, want: []string{"kind: JobDefinition", "name: def"}},
+		{args: []string{"describe", "jr", "second"}, want: []string{"Name:", "second", "Job Definition:", "def", "Status:", "Pending"}},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			out, _, err := runCommand(client, test.args...)
+			if err != nil {
+				t.Fatalf("%v", err)
+			}
+			for _, want := range test.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("printed %q, want %q in it", out, want)
+				}
+			}
+		})
+	}
+
+	if _, _, err := runCommand(client, "get", "jr", "missing"); !apierrors.IsNotFound(err) {
+		t.Errorf("get of a missing job run returned %v, want NotFound", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	client := fake.NewSimpleClientset(newJobRun("first", nil), newJobRun("second", nil))
+	if _, _, err := runCommand(client, "delete", "jr", "first", "second"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	list, err := client.CodeengineV1beta1().JobRuns("ns").List(context.Background(), metav1.ListOptions{})
+	if err != nil || len(list.Items) != 0 {
+		t.Errorf("job runs left after delete: %v, %v", list, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	failed := newJobRun("failed", nil)
+	failed.Status.ManageConditions(clock.RealClock{}).MarkFailed("IndexFailed", "index 3 failed")
+	indices := "3"
+	failed.Status.FailedIndices = &indices
+
+	tests := []struct {
+		name    string
+		jobRun  *v1beta1.JobRun
+		args    []string
+		wantErr string
+		wantOut bool
+	}{{
+		name:    "complete",
+		jobRun:  newJobRun("run", func(m *v1beta1.JobRunConditionManager) { m.MarkComplete() }),
+		wantOut: true,
+	}, {
+		name:    "failed",
+		jobRun:  failed,
+		wantErr: "failed indices: 3",
+		wantOut: true,
+	}, {
+		name:    "timeout",
+		jobRun:  newJobRun("run", func(m *v1beta1.JobRunConditionManager) { m.MarkRunning() }),
+		args:    []string{"--timeout", "50ms"},
+		wantErr: "timed out",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset(test.jobRun)
+			out, _, err := runCommand(client, append([]string{"watch", test.jobRun.Name}, test.args...)...)
+			switch {
+			case test.wantErr == "" && err != nil:
+				t.Fatalf("watch: %v", err)
+			case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+				t.Fatalf("watch returned %v, want an error with %q", err, test.wantErr)
+			}
+			if got := strings.Contains(out, test.jobRun.Name); got != test.wantOut {
+				t.Errorf("watch printed %q", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-cejob submits and inspects Code Engine job runs and job definitions.
+// Installed on the PATH it is available as kubectl plugin: kubectl cejob.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned"
+)
+
+const usage = `Submit and inspect Code Engine job runs and job definitions.
+
+Usage:
+  kubectl-cejob <command> [flags] [args]
+
+Commands:
+%s
+Kinds:
+  jobrun (jobruns, jr), jobdefinition (jobdefinitions, jd)
+
+Use "kubectl-cejob <command> --help" for the flags of a command.
+`
+
+// command is a subcommand of the CLI.
+type command interface {
+	// usage returns the arguments and a short description of the command.
+	usage() (args, description string)
+	// addFlags registers the flags specific to the command.
+	addFlags(fs *pflag.FlagSet)
+	// run executes the command with the positional arguments.
+	run(ctx context.Context, o *options, args []string) error
+}
+
+var commands = map[string]func() command{
+	"submit":   func() command { return &submitCommand{} },
+	"get":      func() command { return &getCommand{} },
+	"list":     func() command { return &listCommand{} },
+	"describe": func() command { return &describeCommand{} },
+	"watch":    func() command { return &watchCommand{} },
+	"delete":   func() command { return &deleteCommand{} },
+}
+
+// options are the flags shared by all commands.
+type options struct {
+	kubeconfig string
+	context    string
+	namespace  string
+	output     string
+
+	client versioned.Interface
+	// out and errOut receive the output and the progress reports of the commands.
+	out    io.Writer
+	errOut io.Writer
+}
+
+func (o *options) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file to use")
+	fs.StringVar(&o.context, "context", "", "The name of the kubeconfig context to use")
+	fs.StringVarP(&o.namespace, "namespace", "n", "", "The namespace to use, defaults to the namespace of the kubeconfig context")
+	fs.StringVarP(&o.output, "output", "o", outputTable, "Output format: table, json or yaml")
+}
+
+// complete creates the client and resolves the namespace.
+func (o *options) complete() error {
+	switch o.output {
+	case outputTable, outputJSON, outputYAML:
+	default:
+		return fmt.Errorf("unsupported output format %q", o.output)
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: o.context}
+	config := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	if o.namespace == "" {
+		ns, _, err := config.Namespace()
+		if err != nil {
+			return err
+		}
+		o.namespace = ns
+	}
+
+	restConfig, err := config.ClientConfig()
+	if err != nil {
+		return err
+	}
+	o.client, err = versioned.NewForConfig(restConfig)
+	return err
+}
+
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var list string
+	for _, name := range names {
+		args, description := commands[name]().usage()
+		list += fmt.Sprintf("  %-32s %s\n", name+" "+args, description)
+	}
+	fmt.Fprintf(os.Stderr, usage, list)
+}
+
+func run(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		printUsage()
+		return nil
+	}
+
+	newCommand, ok := commands[args[0]]
+	if !ok {
+		printUsage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	cmd := newCommand()
+
+	o := &options{out: os.Stdout, errOut: os.Stderr}
+	fs := pflag.NewFlagSet(args[0], pflag.ContinueOnError)
+	o.addFlags(fs)
+	cmd.addFlags(fs)
+	fs.Usage = func() {
+		cmdArgs, description := cmd.usage()
+		fmt.Fprintf(os.Stderr, "%s\n\nUsage:\n  %s\n\nFlags:\n", description, strings.Join(strings.Fields("kubectl-cejob "+args[0]+" "+cmdArgs+" [flags]"), " "))
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	if err := o.complete(); err != nil {
+		return err
+	}
+	return cmd.run(ctx, o, fs.Args())
+}
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// printObject writes obj in the given format. Lists of objects are printed as a single table.
+func printObject(w io.Writer, format string, obj runtime.Object) error {
+	setTypeMeta(obj)
+
+	switch format {
+	case outputJSON:
+		data, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case outputYAML:
+		data, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	switch o := obj.(type) {
+	case *v1beta1.JobRun:
+		printJobRunTable(tw, []v1beta1.JobRun{*o})
+	case *v1beta1.JobRunList:
+		printJobRunTable(tw, o.Items)
+	case *v1beta1.JobDefinition:
+		printJobDefinitionTable(tw, []v1beta1.JobDefinition{*o})
+	case *v1beta1.JobDefinitionList:
+		printJobDefinitionTable(tw, o.Items)
+	default:
+		return fmt.Errorf("unsupported object %T", obj)
+	}
+	return tw.Flush()
+}
+
+// setTypeMeta restores apiVersion and kind, which the clientset strips from decoded objects.
+func setTypeMeta(obj runtime.Object) {
+	kind := ""
+	switch o := obj.(type) {
+	case *v1beta1.JobRun:
+		kind = "JobRun"
+	case *v1beta1.JobRunList:
+		kind = "JobRunList"
+		for i := range o.Items {
+			setTypeMeta(&o.Items[i])
+		}
+	case *v1beta1.JobDefinition:
+		kind = "JobDefinition"
+	case *v1beta1.JobDefinitionList:
+		kind = "JobDefinitionList"
+		for i := range o.Items {
+			setTypeMeta(&o.Items[i])
+		}
+	default:
+		return
+	}
+	obj.GetObjectKind().SetGroupVersionKind(v1beta1.SchemeGroupVersion.WithKind(kind))
+}
+
+func printJobRunTable(w io.Writer, items []v1beta1.JobRun) {
+	fmt.Fprintln(w, "NAME\tJOB DEFINITION\tSTATUS\tREQUESTED\tPENDING\tRUNNING\tSUCCEEDED\tFAILED\tAGE")
+	for i := range items {
+		jr := &items[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%s\n",
+			jr.Name, valueOrNone(jr.Spec.JobDefinitionRef), jr.Phase(),
+			jr.Status.Requested, jr.Status.Pending, jr.Status.Running, jr.Status.Succeeded, jr.Status.Failed,
+			age(jr.CreationTimestamp))
+	}
+}
+
+func printJobDefinitionTable(w io.Writer, items []v1beta1.JobDefinition) {
+	fmt.Fprintln(w, "NAME\tIMAGE\tARRAY SPEC\tRETRY LIMIT\tMAX EXECUTION TIME\tAGE")
+	for i := range items {
+		jd := &items[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			jd.Name, images(&jd.Spec.Template), stringOrNone(jd.Spec.ArraySpec),
+			int64OrNone(jd.Spec.RetryLimit), int64OrNone(jd.Spec.MaxExecutionTime),
+			age(jd.CreationTimestamp))
+	}
+}
+
+func images(t *v1beta1.JobPodTemplate) string {
+	var images []string
+	for _, c := range t.Containers {
+		images = append(images, c.Image)
+	}
+	return valueOrNone(strings.Join(images, ","))
+}
+
+func age(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return time.Since(t.Time).Round(time.Second).String()
+}
+
+func valueOrNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+func stringOrNone(s *string) string {
+	if s == nil {
+		return "<none>"
+	}
+	return valueOrNone(*s)
+}
+
+func int64OrNone(i *int64) string {
+	if i == nil {
+		return "<none>"
+	}
+	return fmt.Sprint(*i)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// applyconfiguration-gen runs the applyconfiguration-gen of k8s.io/code-generator
+// with the apply configurations of client-go registered for the Kubernetes types
+// referenced by the codeengine API types.
+//
+// The --external-applyconfigurations flag of code-generator v0.25 can't parse
+// package paths containing dots, so the mapping is set up here instead.
+package main
+
+import (
+	"flag"
+
+	"github.com/spf13/pflag"
+	generatorargs "k8s.io/code-generator/cmd/applyconfiguration-gen/args"
+	"k8s.io/code-generator/cmd/applyconfiguration-gen/generators"
+	"k8s.io/code-generator/pkg/util"
+	"k8s.io/gengo/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	metav1Package = "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1Package = "k8s.io/api/core/v1"
+
+	metav1ApplyConfigurations = "k8s.io/client-go/applyconfigurations/meta/v1"
+	corev1ApplyConfigurations = "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+// externalApplyConfigurations maps the Kubernetes types used by the API types
+// to the package of their apply configurations.
+var externalApplyConfigurations = map[types.Name]string{
+	{Package: metav1Package, Name: "TypeMeta"}:       metav1ApplyConfigurations,
+	{Package: metav1Package, Name: "ObjectMeta"}:     metav1ApplyConfigurations,
+	{Package: metav1Package, Name: "OwnerReference"}: metav1ApplyConfigurations,
+
+	{Package: corev1Package, Name: "Container"}:                corev1ApplyConfigurations,
+	{Package: corev1Package, Name: "LocalObjectReference"}:     corev1ApplyConfigurations,
+	{Package: corev1Package, Name: "Volume"}:                   corev1ApplyConfigurations,
+	{Package: corev1Package, Name: "Toleration"}:               corev1ApplyConfigurations,
+	{Package: corev1Package, Name: "Affinity"}:                 corev1ApplyConfigurations,
+	{Package: corev1Package, Name: "TopologySpreadConstraint"}: corev1ApplyConfigurations,
+}
+
+func main() {
+	klog.InitFlags(nil)
+	genericArgs, customArgs := generatorargs.NewDefaults()
+	for name, pkg := range externalApplyConfigurations {
+		customArgs.ExternalApplyConfigurations[name] = pkg
+	}
+	genericArgs.GoHeaderFilePath = util.BoilerplatePath()
+	genericArgs.AddFlags(pflag.CommandLine)
+	customArgs.AddFlags(pflag.CommandLine, "k8s.io/kubernetes/pkg/apis")
+	if err := flag.Set("logtostderr", "true"); err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
+	pflag.Parse()
+
+	if err := generatorargs.Validate(genericArgs); err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+
+	if err := genericArgs.Execute(
+		generators.NameSystems(),
+		generators.DefaultNameSystem(),
+		generators.Packages,
+	); err != nil {
+		klog.Fatalf("Error: %v", err)
+	}
+}
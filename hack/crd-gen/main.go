@@ -0,0 +1,314 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// crd-gen writes the CustomResourceDefinitions of the codeengine API types,
+// with structural schemas built from the OpenAPI definitions generated by openapi-gen.
+//
+// Usage:
+//
+//	go run ./hack/crd-gen --output-dir config
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"sigs.k8s.io/yaml"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/openapi"
+)
+
+const (
+	apiPackage  = "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	refPrefix   = "#/definitions/"
+	fileHeader  = "# Code generated by hack/crd-gen. DO NOT EDIT.\n"
+	licensePath = "hack/boilerplate/boilerplate.sh.txt"
+)
+
+// resource describes a CustomResourceDefinition to be generated.
+type resource struct {
+	file     string
+	kind     string
+	singular string
+	plural   string
+	columns  []printerColumn
+}
+
+var resources = []resource{{
+	file:     "300-jobdefinition.yaml",
+	kind:     "JobDefinition",
+	singular: "jobdefinition",
+	plural:   "jobdefinitions",
+	columns: []printerColumn{
+		{Name: "Array", Type: "string", JSONPath: ".spec.arraySpec", Description: "Indices of the job"},
+		{Name: "Retries", Type: "integer", JSONPath: ".spec.retryLimit", Description: "Number of retries of an index"},
+		{Name: "Timeout", Type: "integer", JSONPath: ".spec.maxExecutionTime", Description: "Maximum execution time in seconds", Priority: 1},
+		{Name: "Mode", Type: "string", JSONPath: ".spec.executionMode", Description: "Execution mode of the pods", Priority: 1},
+		{Name: "Parallelism", Type: "integer", JSONPath: ".spec.parallelism", Description: "Maximum number of indices running at the same time", Priority: 1},
+		{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+	},
+}, {
+	file:     "300-jobrun.yaml",
+	kind:     "JobRun",
+	singular: "jobrun",
+	plural:   "jobruns",
+	columns: []printerColumn{
+		{Name: "Phase", Type: "string", JSONPath: `.status.conditions[?(@.type=="Succeeded")].reason`, Description: "Phase of the jobRun, or the reason of its failure"},
+		{Name: "Requested", Type: "integer", JSONPath: ".status.requested", Description: "Number of pods requested but not created yet"},
+		{Name: "Throttled", Type: "integer", JSONPath: ".status.throttled", Description: "Number of indices held back by the parallelism", Priority: 1},
+		{Name: "Pending", Type: "integer", JSONPath: ".status.pending", Description: "Number of pending indices"},
+		{Name: "Running", Type: "integer", JSONPath: ".status.running", Description: "Number of running indices"},
+		{Name: "Succeeded", Type: "integer", JSONPath: ".status.succeeded", Description: "Number of succeeded indices"},
+		{Name: "Failed", Type: "integer", JSONPath: ".status.failed", Description: "Number of failed indices"},
+		{Name: "Unknown", Type: "integer", JSONPath: ".status.unknown", Description: "Number of indices in unknown state", Priority: 1},
+		{Name: "Definition", Type: "string", JSONPath: ".spec.jobDefinitionRef", Description: "Referenced jobDefinition", Priority: 1},
+		{Name: "Age", Type: "date", JSONPath: ".metadata.creationTimestamp"},
+	},
+}}
+
+type customResourceDefinition struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   map[string]string `json:"metadata"`
+	Spec       crdSpec           `json:"spec"`
+}
+
+type crdSpec struct {
+	Group    string       `json:"group"`
+	Names    crdNames     `json:"names"`
+	Scope    string       `json:"scope"`
+	Versions []crdVersion `json:"versions"`
+}
+
+type crdNames struct {
+	Kind       string   `json:"kind"`
+	ListKind   string   `json:"listKind"`
+	Singular   string   `json:"singular"`
+	Plural     string   `json:"plural"`
+	Categories []string `json:"categories"`
+}
+
+type crdVersion struct {
+	Name                     string                 `json:"name"`
+	Served                   bool                   `json:"served"`
+	Storage                  bool                   `json:"storage"`
+	Schema                   map[string]interface{} `json:"schema"`
+	Subresources             map[string]interface{} `json:"subresources"`
+	AdditionalPrinterColumns []printerColumn        `json:"additionalPrinterColumns"`
+}
+
+type printerColumn struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	JSONPath    string `json:"jsonPath"`
+	Description string `json:"description,omitempty"`
+	Priority    int32  `json:"priority,omitempty"`
+}
+
+func main() {
+	outputDir := flag.String("output-dir", "config", "directory the CustomResourceDefinitions are written to")
+	flag.Parse()
+
+	license, err := os.ReadFile(licensePath)
+	if err != nil {
+		log.Fatalf("Failed to read the license header: %v", err)
+	}
+	// The license is kept, the shebang of the shell boilerplate is not.
+	license = []byte(strings.TrimPrefix(string(license), "#!/usr/bin/env bash\n\n"))
+
+	c, err := newConverter()
+	if err != nil {
+		log.Fatalf("Failed to load the OpenAPI definitions: %v", err)
+	}
+
+	for _, r := range resources {
+		schema, err := c.topLevelSchema(apiPackage + "." + r.kind)
+		if err != nil {
+			log.Fatalf("Failed to build the schema of %s: %v", r.kind, err)
+		}
+		crd := customResourceDefinition{
+			APIVersion: "apiextensions.k8s.io/v1",
+			Kind:       "CustomResourceDefinition",
+			Metadata:   map[string]string{"name": r.plural + "." + codeengine.GroupName},
+			Spec: crdSpec{
+				Group: codeengine.GroupName,
+				Names: crdNames{
+					Kind:       r.kind,
+					ListKind:   r.kind + "List",
+					Singular:   r.singular,
+					Plural:     r.plural,
+					Categories: []string{"all", "codeengine"},
+				},
+				Scope: "Namespaced",
+				Versions: []crdVersion{{
+					Name:                     v1beta1.SchemeGroupVersion.Version,
+					Served:                   true,
+					Storage:                  true,
+					Schema:                   map[string]interface{}{"openAPIV3Schema": schema},
+					Subresources:             map[string]interface{}{"status": map[string]interface{}{}},
+					AdditionalPrinterColumns: r.columns,
+				}},
+			},
+		}
+
+		out, err := yaml.Marshal(crd)
+		if err != nil {
+			log.Fatalf("Failed to marshal the CustomResourceDefinition of %s: %v", r.kind, err)
+		}
+		content := string(license) + "\n" + fileHeader + "\n" + string(out)
+		if err := os.WriteFile(filepath.Join(*outputDir, r.file), []byte(content), 0644); err != nil {
+			log.Fatalf("Failed to write %s: %v", r.file, err)
+		}
+	}
+}
+
+// converter turns OpenAPI definitions into structural schemas:
+// references are inlined and everything an apiserver rejects or ignores in a CRD is dropped.
+type converter struct {
+	definitions map[string]map[string]interface{}
+}
+
+func newConverter() (*converter, error) {
+	ref := func(name string) spec.Ref {
+		return spec.MustCreateRef(refPrefix + name)
+	}
+	c := &converter{definitions: map[string]map[string]interface{}{}}
+	for name, definition := range openapi.GetDefinitions(common.ReferenceCallback(ref)) {
+		b, err := json.Marshal(definition.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		var schema map[string]interface{}
+		if err := json.Unmarshal(b, &schema); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		c.definitions[name] = schema
+	}
+	return c, nil
+}
+
+// topLevelSchema returns the schema of a resource. The apiserver validates the metadata itself,
+// and the spec and status are optional since the status is dropped on creation.
+func (c *converter) topLevelSchema(name string) (map[string]interface{}, error) {
+	schema, err := c.definition(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	delete(schema, "required")
+	return schema, nil
+}
+
+func (c *converter) definition(name string, visiting []string) (map[string]interface{}, error) {
+	switch name {
+	case "k8s.io/apimachinery/pkg/apis/meta/v1.ObjectMeta":
+		return map[string]interface{}{"type": "object"}, nil
+	case "k8s.io/apimachinery/pkg/util/intstr.IntOrString", "k8s.io/apimachinery/pkg/api/resource.Quantity":
+		return map[string]interface{}{
+			"anyOf":                      []interface{}{map[string]interface{}{"type": "integer"}, map[string]interface{}{"type": "string"}},
+			"x-kubernetes-int-or-string": true,
+		}, nil
+	}
+
+	for _, v := range visiting {
+		if v == name {
+			return nil, fmt.Errorf("recursive definition %s", strings.Join(append(visiting, name), " -> "))
+		}
+	}
+	schema, ok := c.definitions[name]
+	if !ok {
+		return nil, fmt.Errorf("missing definition %s", name)
+	}
+	return c.convert(schema, append(visiting, name))
+}
+
+func (c *converter) convert(schema map[string]interface{}, visiting []string) (map[string]interface{}, error) {
+	if ref, ok := schema["$ref"].(string); ok {
+		resolved, err := c.definition(strings.TrimPrefix(ref, refPrefix), visiting)
+		if err != nil {
+			return nil, err
+		}
+		// The description of the field takes precedence over the one of its type.
+		if description, ok := schema["description"]; ok {
+			resolved["description"] = description
+		}
+		return resolved, nil
+	}
+
+	result := map[string]interface{}{}
+	for key, value := range schema {
+		switch key {
+		case "default":
+			// openapi-gen emits the zero value as default of every required field,
+			// which would defeat the validation of the field.
+			if isZero(value) {
+				continue
+			}
+		case "x-kubernetes-patch-strategy", "x-kubernetes-patch-merge-key", "x-kubernetes-unions":
+			// Patch strategies and unions are not supported by CRDs.
+			continue
+		case "type":
+			// openapi-gen emits types as lists, CRDs accept a single type.
+			if types, ok := value.([]interface{}); ok && len(types) == 1 {
+				value = types[0]
+			}
+		case "items", "additionalProperties":
+			nested, ok := value.(map[string]interface{})
+			if !ok {
+				break
+			}
+			converted, err := c.convert(nested, visiting)
+			if err != nil {
+				return nil, err
+			}
+			value = converted
+		case "properties":
+			properties := map[string]interface{}{}
+			for property, nested := range value.(map[string]interface{}) {
+				converted, err := c.convert(nested.(map[string]interface{}), visiting)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", property, err)
+				}
+				properties[property] = converted
+			}
+			value = properties
+		}
+		result[key] = value
+	}
+	return result, nil
+}
+
+func isZero(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return value == nil
+}
@@ -31,11 +31,39 @@ group "Kubernetes Codegen"
 # --output-base    because this script should also be able to run inside the vendor dir of
 #                  k8s.io/kubernetes. The output-base is needed for the generators to output into the vendor dir
 #                  instead of the $GOPATH directly. For normal projects this can be dropped.
-${CODEGEN_PKG}/generate-groups.sh "deepcopy,client,informer,lister" \
+${CODEGEN_PKG}/generate-groups.sh "deepcopy,informer,lister" \
   github.com/rafalbigaj/code-engine-batch-job-client/pkg/client github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis \
   "codeengine:v1beta1" \
   --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
 
+# generate-groups.sh passes its extra flags to every generator, so the apply configurations
+# and the clientset, which refers to them, are generated separately.
+# hack/applyconfiguration-gen maps the Kubernetes types to the apply configurations of client-go.
+go run ${REPO_ROOT_DIR}/hack/applyconfiguration-gen \
+  --input-dirs github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1 \
+  --output-package github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/applyconfiguration \
+  --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
+
+go run k8s.io/code-generator/cmd/client-gen \
+  --clientset-name versioned \
+  --input-base "" \
+  --input github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1 \
+  --output-package github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset \
+  --apply-configuration-package github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/applyconfiguration \
+  --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
+
+group "OpenAPI Codegen"
+
+# The definitions of the Kubernetes types referenced by our types are generated as well,
+# the CustomResourceDefinitions need them to build complete structural schemas.
+# Like the generators above, it writes to the checkout in $GOPATH/src.
+go run k8s.io/kube-openapi/cmd/openapi-gen \
+  --input-dirs github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1,k8s.io/api/core/v1,k8s.io/apimachinery/pkg/apis/meta/v1,k8s.io/apimachinery/pkg/runtime,k8s.io/apimachinery/pkg/api/resource,k8s.io/apimachinery/pkg/util/intstr,k8s.io/apimachinery/pkg/version \
+  --output-package github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/openapi \
+  --output-file-base zz_generated.openapi \
+  --report-filename /dev/null \
+  --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
+
 group "Knative Codegen"
 
 # Knative Injection
@@ -44,6 +72,10 @@ ${KNATIVE_CODEGEN_PKG}/hack/generate-knative.sh "injection" \
   "codeengine:v1beta1" \
   --go-header-file ${REPO_ROOT_DIR}/hack/boilerplate/boilerplate.go.txt
 
+group "CustomResourceDefinitions"
+
+go run ${REPO_ROOT_DIR}/hack/crd-gen --output-dir ${REPO_ROOT_DIR}/config
+
 group "Update deps post-codegen"
 
 # Make sure our dependencies are up-to-date
@@ -11,6 +11,7 @@ package v1beta1
 import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
 )
 
 // JobRunConditionType are the condition types of JobRun
@@ -26,6 +27,9 @@ const (
 	JobComplete JobRunConditionType = "Complete"
 	// JobFailed means the job has failed its execution.
 	JobFailed JobRunConditionType = "Failed"
+	// JobSucceeded is the top-level condition of the job, as known by knative tooling.
+	// It is Unknown while the job is pending or running, True once complete and False once failed.
+	JobSucceeded JobRunConditionType = JobRunConditionType(apis.ConditionSucceeded)
 )
 
 // JobRunCondition describes current state of a jobRun.
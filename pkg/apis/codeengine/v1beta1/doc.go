@@ -24,6 +24,7 @@ limitations under the License.
  ******************************************************************************/
 
 // +k8s:deepcopy-gen=package
+// +k8s:openapi-gen=true
 // +groupName=codeengine.cloud.ibm.com
 
 // Package v1beta1 is the v1beta1 version of the API.
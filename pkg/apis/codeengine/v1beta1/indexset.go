@@ -0,0 +1,298 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IndexRange is an inclusive range of job indices, e.g. 3-7.
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type IndexRange struct {
+	Start int64
+	End   int64
+}
+
+// Count returns the number of indices in the range.
+func (r IndexRange) Count() int64 {
+	return r.End - r.Start + 1
+}
+
+// String returns the range in index notation: "3" or "3-7".
+func (r IndexRange) String() string {
+	if r.Start == r.End {
+		return strconv.FormatInt(r.Start, 10)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// IndexSet is a set of job indices, as used by arraySpec, failedIndices and succeededIndices.
+// It is stored as sorted ranges which neither overlap nor touch each other,
+// so its size does not depend on the number of indices it holds.
+// The zero value is an empty set.
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type IndexSet struct {
+	ranges []IndexRange
+}
+
+// ParseIndexSet parses the index notation, e.g. "1,3,6,9" or "1-5, 7 - 8, 10".
+// White spaces are allowed around indices and separators, ranges may overlap
+// and may be given in any order. An empty string yields an empty set.
+// Indices are unsigned decimal numbers not greater than MaxIndexValue.
+func ParseIndexSet(s string) (IndexSet, error) {
+	if strings.TrimSpace(s) == "" {
+		return IndexSet{}, nil
+	}
+
+	var ranges []IndexRange
+	for _, part := range strings.Split(s, ",") {
+		r, err := parseIndexRange(part)
+		if err != nil {
+			return IndexSet{}, err
+		}
+		ranges = append(ranges, r)
+	}
+	return NewIndexSetFromRanges(ranges...), nil
+}
+
+func parseIndexRange(s string) (IndexRange, error) {
+	bounds := strings.Split(s, "-")
+	if len(bounds) > 2 {
+		return IndexRange{}, fmt.Errorf("invalid index range %q", strings.TrimSpace(s))
+	}
+
+	start, err := parseIndex(bounds[0])
+	if err != nil {
+		return IndexRange{}, err
+	}
+	if len(bounds) == 1 {
+		return IndexRange{Start: start, End: start}, nil
+	}
+
+	end, err := parseIndex(bounds[1])
+	if err != nil {
+		return IndexRange{}, err
+	}
+	if start > end {
+		return IndexRange{}, fmt.Errorf("invalid index range %q: start is greater than end", strings.TrimSpace(s))
+	}
+	return IndexRange{Start: start, End: end}, nil
+}
+
+func parseIndex(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("missing index")
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("invalid index %q", s)
+		}
+	}
+	idx, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || idx > MaxIndexValue {
+		return 0, fmt.Errorf("index %s exceeds the maximum of %d", s, MaxIndexValue)
+	}
+	return idx, nil
+}
+
+// MustParseIndexSet is like ParseIndexSet but panics if the notation is invalid.
+func MustParseIndexSet(s string) IndexSet {
+	set, err := ParseIndexSet(s)
+	if err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// NewIndexSet returns a set holding the given indices.
+func NewIndexSet(indices ...int64) IndexSet {
+	ranges := make([]IndexRange, 0, len(indices))
+	for _, idx := range indices {
+		ranges = append(ranges, IndexRange{Start: idx, End: idx})
+	}
+	return NewIndexSetFromRanges(ranges...)
+}
+
+// NewIndexSetFromRanges returns a set holding all indices of the given ranges.
+// Ranges with start greater than end are ignored.
+func NewIndexSetFromRanges(ranges ...IndexRange) IndexSet {
+	sorted := make([]IndexRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.Start <= r.End {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var merged []IndexRange
+	for _, r := range sorted {
+		if n := len(merged); n > 0 && (merged[n-1].End == math.MaxInt64 || r.Start <= merged[n-1].End+1) {
+			if r.End > merged[n-1].End {
+				merged[n-1].End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return IndexSet{ranges: merged}
+}
+
+// Ranges returns the normalised ranges of the set in ascending order.
+func (s IndexSet) Ranges() []IndexRange {
+	return append([]IndexRange(nil), s.ranges...)
+}
+
+// IsEmpty returns true if the set holds no index.
+func (s IndexSet) IsEmpty() bool {
+	return len(s.ranges) == 0
+}
+
+// Count returns the number of indices in the set.
+func (s IndexSet) Count() int64 {
+	var count int64
+	for _, r := range s.ranges {
+		count += r.Count()
+	}
+	return count
+}
+
+// Min returns the lowest index of the set, ok is false if the set is empty.
+func (s IndexSet) Min() (idx int64, ok bool) {
+	if s.IsEmpty() {
+		return 0, false
+	}
+	return s.ranges[0].Start, true
+}
+
+// Max returns the highest index of the set, ok is false if the set is empty.
+func (s IndexSet) Max() (idx int64, ok bool) {
+	if s.IsEmpty() {
+		return 0, false
+	}
+	return s.ranges[len(s.ranges)-1].End, true
+}
+
+// Contains returns true if idx belongs to the set.
+func (s IndexSet) Contains(idx int64) bool {
+	i := sort.Search(len(s.ranges), func(i int) bool { return s.ranges[i].End >= idx })
+	return i < len(s.ranges) && s.ranges[i].Start <= idx
+}
+
+// Each calls fn for every index of the set in ascending order,
+// until fn returns false.
+func (s IndexSet) Each(fn func(idx int64) bool) {
+	for _, r := range s.ranges {
+		for idx := r.Start; ; idx++ {
+			if !fn(idx) {
+				return
+			}
+			// Checked before incrementing, not to overflow at math.MaxInt64.
+			if idx == r.End {
+				break
+			}
+		}
+	}
+}
+
+// Equal returns true if both sets hold the same indices.
+func (s IndexSet) Equal(other IndexSet) bool {
+	if len(s.ranges) != len(other.ranges) {
+		return false
+	}
+	for i := range s.ranges {
+		if s.ranges[i] != other.ranges[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Union returns the indices that belong to s or other.
+func (s IndexSet) Union(other IndexSet) IndexSet {
+	ranges := make([]IndexRange, 0, len(s.ranges)+len(other.ranges))
+	ranges = append(ranges, s.ranges...)
+	ranges = append(ranges, other.ranges...)
+	return NewIndexSetFromRanges(ranges...)
+}
+
+// Intersect returns the indices that belong to both s and other.
+func (s IndexSet) Intersect(other IndexSet) IndexSet {
+	var ranges []IndexRange
+	for i, j := 0, 0; i < len(s.ranges) && j < len(other.ranges); {
+		a, b := s.ranges[i], other.ranges[j]
+		start, end := max64(a.Start, b.Start), min64(a.End, b.End)
+		if start <= end {
+			ranges = append(ranges, IndexRange{Start: start, End: end})
+		}
+		if a.End < b.End {
+			i++
+		} else {
+			j++
+		}
+	}
+	return IndexSet{ranges: ranges}
+}
+
+// Difference returns the indices that belong to s but not to other.
+func (s IndexSet) Difference(other IndexSet) IndexSet {
+	var ranges []IndexRange
+	j := 0
+	for _, r := range s.ranges {
+		start, covered := r.Start, false
+		// Skip ranges of other that end before the current range.
+		for j < len(other.ranges) && other.ranges[j].End < start {
+			j++
+		}
+		for k := j; k < len(other.ranges) && other.ranges[k].Start <= r.End; k++ {
+			if other.ranges[k].Start > start {
+				ranges = append(ranges, IndexRange{Start: start, End: other.ranges[k].Start - 1})
+			}
+			if other.ranges[k].End >= r.End {
+				start = r.End
+				covered = true
+				break
+			}
+			start = other.ranges[k].End + 1
+		}
+		if !covered {
+			ranges = append(ranges, IndexRange{Start: start, End: r.End})
+		}
+	}
+	return IndexSet{ranges: ranges}
+}
+
+// String returns the canonical index notation of the set, e.g. "1-3,5,7-9".
+func (s IndexSet) String() string {
+	parts := make([]string, 0, len(s.ranges))
+	for _, r := range s.ranges {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,160 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseIndexSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", in: "", want: ""},
+		{name: "blank", in: "  ", want: ""},
+		{name: "single", in: "3", want: "3"},
+		{name: "list", in: "1,3,6,9", want: "1,3,6,9"},
+		{name: "ranges with spaces", in: "1-5, 7 - 8, 10", want: "1-5,7-8,10"},
+		{name: "touching ranges", in: "1-3,4-6,7", want: "1-7"},
+		{name: "overlapping unordered", in: "5-9,0-6,2", want: "0-9"},
+		{name: "duplicates", in: "2,2,2", want: "2"},
+		{name: "zero", in: "0", want: "0"},
+		{name: "max index", in: "0-9999999", want: "0-9999999"},
+		{name: "above max index", in: "10000000", wantErr: true},
+		{name: "above int64", in: "99999999999999999999", wantErr: true},
+		{name: "plus sign", in: "+3", wantErr: true},
+		{name: "minus sign", in: "-3", wantErr: true},
+		{name: "reversed range", in: "5-3", wantErr: true},
+		{name: "open range", in: "3-", wantErr: true},
+		{name: "three bounds", in: "1-2-3", wantErr: true},
+		{name: "empty element", in: "1,,2", wantErr: true},
+		{name: "letters", in: "a", wantErr: true},
+		{name: "inner space", in: "1 2", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseIndexSet(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseIndexSet(%q) = %q, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseIndexSet(%q) returned error: %v", tt.in, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseIndexSet(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if again := MustParseIndexSet(got.String()); !again.Equal(got) {
+				t.Errorf("String of %q doesn't round-trip: got %q", tt.in, again)
+			}
+		})
+	}
+}
+
+func TestIndexSetCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{in: "", want: 0},
+		{in: "7", want: 1},
+		{in: "0-9", want: 10},
+		{in: "0-9,20,30-31", want: 13},
+		{in: "0-9999999", want: 10000000},
+	}
+	for _, tt := range tests {
+		if got := MustParseIndexSet(tt.in).Count(); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIndexSetOperations(t *testing.T) {
+	tests := []struct {
+		a, b                         string
+		union, intersect, difference string
+	}{
+		{a: "", b: "", union: "", intersect: "", difference: ""},
+		{a: "0-9", b: "", union: "0-9", intersect: "", difference: "0-9"},
+		{a: "", b: "0-9", union: "0-9", intersect: "", difference: ""},
+		{a: "0-9", b: "0-9", union: "0-9", intersect: "0-9", difference: ""},
+		{a: "0-9", b: "3-5", union: "0-9", intersect: "3-5", difference: "0-2,6-9"},
+		{a: "0-9", b: "0,9", union: "0-9", intersect: "0,9", difference: "1-8"},
+		{a: "0-4", b: "5-9", union: "0-9", intersect: "", difference: "0-4"},
+		{a: "0-4", b: "6-9", union: "0-4,6-9", intersect: "", difference: "0-4"},
+		{a: "0-5,10-15", b: "3-12", union: "0-15", intersect: "3-5,10-12", difference: "0-2,13-15"},
+		{a: "1,3,5", b: "0-9", union: "0-9", intersect: "1,3,5", difference: ""},
+		{a: "0-9999999", b: "9999999", union: "0-9999999", intersect: "9999999", difference: "0-9999998"},
+	}
+	for _, tt := range tests {
+		a, b := MustParseIndexSet(tt.a), MustParseIndexSet(tt.b)
+		if got := a.Union(b).String(); got != tt.union {
+			t.Errorf("%q union %q = %q, want %q", tt.a, tt.b, got, tt.union)
+		}
+		if got := a.Intersect(b).String(); got != tt.intersect {
+			t.Errorf("%q intersect %q = %q, want %q", tt.a, tt.b, got, tt.intersect)
+		}
+		if got := a.Difference(b).String(); got != tt.difference {
+			t.Errorf("%q difference %q = %q, want %q", tt.a, tt.b, got, tt.difference)
+		}
+	}
+}
+
+func TestIndexSetContains(t *testing.T) {
+	s := MustParseIndexSet("1-3,7,10-12")
+	for idx, want := range map[int64]bool{0: false, 1: true, 3: true, 4: false, 7: true, 9: false, 12: true, 13: false} {
+		if got := s.Contains(idx); got != want {
+			t.Errorf("Contains(%d) = %v, want %v", idx, got, want)
+		}
+	}
+}
+
+func TestIndexSetMaxInt64(t *testing.T) {
+	top := IndexRange{Start: math.MaxInt64 - 2, End: math.MaxInt64}
+	s := NewIndexSetFromRanges(top, IndexRange{Start: math.MaxInt64 - 1, End: math.MaxInt64})
+	if got := s.Ranges(); len(got) != 1 || got[0] != top {
+		t.Fatalf("NewIndexSetFromRanges = %v, want [%v]", got, top)
+	}
+
+	var visited []int64
+	s.Each(func(idx int64) bool {
+		visited = append(visited, idx)
+		return len(visited) < 10
+	})
+	if len(visited) != 3 || visited[2] != math.MaxInt64 {
+		t.Errorf("Each visited %v, want the 3 indices up to math.MaxInt64", visited)
+	}
+
+	if got := s.Difference(NewIndexSet(math.MaxInt64)); !got.Equal(NewIndexSetFromRanges(IndexRange{Start: math.MaxInt64 - 2, End: math.MaxInt64 - 1})) {
+		t.Errorf("Difference = %v", got.Ranges())
+	}
+	if got := s.Difference(s); !got.IsEmpty() {
+		t.Errorf("Difference with itself = %v, want empty", got.Ranges())
+	}
+	if got := s.Union(NewIndexSet(0)).Count(); got != 4 {
+		t.Errorf("Union count = %d, want 4", got)
+	}
+}
+
+func TestIndexSetEachStops(t *testing.T) {
+	var visited []int64
+	MustParseIndexSet("0-9").Each(func(idx int64) bool {
+		visited = append(visited, idx)
+		return idx < 2
+	})
+	if len(visited) != 3 {
+		t.Errorf("Each visited %v, want it to stop after 3 indices", visited)
+	}
+}
@@ -0,0 +1,99 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GetMainContainer returns the main container of the template, which is the container
+// named by mainContainer or the first one if it's not set. nil is returned if there is none.
+func (t *JobPodTemplate) GetMainContainer() *corev1.Container {
+	if i := t.mainContainerIndex(); i >= 0 {
+		return &t.Containers[i]
+	}
+	return nil
+}
+
+// mainContainerIndex returns the index of the main container, or -1 if there is none.
+func (t *JobPodTemplate) mainContainerIndex() int {
+	if t.MainContainer == "" {
+		if len(t.Containers) == 0 {
+			return -1
+		}
+		return 0
+	}
+	for i := range t.Containers {
+		if t.Containers[i].Name == t.MainContainer {
+			return i
+		}
+	}
+	return -1
+}
+
+// IndexPhase returns the phase of the index run by the given pod of the jobRun.
+// The phase follows the main container once it terminated, since sidecars may keep
+// the pod running: the index succeeded if the main container exited with code 0
+// and failed otherwise. Until then, and for pods which failed before the main container
+// terminated, e.g. because of a failed init container, the phase of the pod is returned.
+func (j *JobRun) IndexPhase(pod *corev1.Pod) corev1.PodPhase {
+	cs := mainContainerStatus(pod, j.Spec.JobDefinitionSpec.Template.MainContainer)
+	if cs == nil || cs.State.Terminated == nil {
+		return pod.Status.Phase
+	}
+	if cs.State.Terminated.ExitCode == 0 {
+		return corev1.PodSucceeded
+	}
+	return corev1.PodFailed
+}
+
+// mainContainerStatus returns the status of the main container of the pod,
+// which is the first container unless mainContainer is set.
+func mainContainerStatus(pod *corev1.Pod, mainContainer string) *corev1.ContainerStatus {
+	if mainContainer == "" {
+		if len(pod.Spec.Containers) == 0 {
+			return nil
+		}
+		mainContainer = pod.Spec.Containers[0].Name
+	}
+	for i := range pod.Status.ContainerStatuses {
+		if pod.Status.ContainerStatuses[i].Name == mainContainer {
+			return &pod.Status.ContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+// terminationOfIndex returns the termination which determines the outcome of the pod:
+// the one of the main container, or else the first failed init container.
+// The first failed container is used for pods without a spec.
+func terminationOfIndex(pod *corev1.Pod, mainContainer string) *corev1.ContainerStateTerminated {
+	if len(pod.Spec.Containers) > 0 {
+		if cs := mainContainerStatus(pod, mainContainer); cs != nil && cs.State.Terminated != nil {
+			return cs.State.Terminated
+		}
+		for _, cs := range pod.Status.InitContainerStatuses {
+			if t := cs.State.Terminated; t != nil && t.ExitCode != 0 {
+				return t
+			}
+		}
+		return nil
+	}
+
+	var last *corev1.ContainerStateTerminated
+	for _, cs := range pod.Status.ContainerStatuses {
+		if t := cs.State.Terminated; t != nil {
+			last = t
+			if t.ExitCode != 0 {
+				break
+			}
+		}
+	}
+	return last
+}
@@ -0,0 +1,74 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GetExecutionMode returns the execution mode of the job.
+// Specs of older clients don't set executionMode but the CE_EXECUTION_MODE environment variable
+// of the main container, which is used as fallback. The mode defaults to ExecutionModeTask.
+func (jds *JobDefinitionSpec) GetExecutionMode() ExecutionMode {
+	if jds.ExecutionMode != "" {
+		return jds.ExecutionMode
+	}
+	if mode, ok := jds.executionModeFromEnv(); ok {
+		return mode
+	}
+	return ExecutionModeTask
+}
+
+// IsDaemon returns true if the pods of the job run in daemon mode.
+func (jds *JobDefinitionSpec) IsDaemon() bool {
+	return jds.GetExecutionMode() == ExecutionModeDaemon
+}
+
+// ExecutionModeFromEnv translates the CE_EXECUTION_MODE environment variable of the main container,
+// as set by older clients, into executionMode unless executionMode is already set, in which case
+// validation reports a conflict. The variable is kept, so older servers, which ignore executionMode,
+// still run a defaulted spec in daemon mode.
+func (jds *JobDefinitionSpec) ExecutionModeFromEnv() {
+	if jds.ExecutionMode != "" {
+		return
+	}
+	if mode, ok := jds.executionModeFromEnv(); ok {
+		jds.ExecutionMode = mode
+	}
+}
+
+// ExecutionModeToEnv translates executionMode into the CE_EXECUTION_MODE environment variable
+// of the main container, which older servers rely on to detect daemon mode.
+// Nothing is set for task mode, nor if the template has no container.
+// It is called on the submit path, e.g. by the Build method of the builder package.
+func (jds *JobDefinitionSpec) ExecutionModeToEnv() {
+	c := jds.Template.GetMainContainer()
+	if jds.ExecutionMode != ExecutionModeDaemon || c == nil {
+		return
+	}
+	c.Env = mergeEnv(c.Env, []corev1.EnvVar{{Name: CEExecutionMode, Value: CEExecutionModeValue}})
+}
+
+// executionModeFromEnv returns the execution mode set by the CE_EXECUTION_MODE environment variable
+// of the main container, if any. Any value other than DAEMON selects task mode.
+func (jds *JobDefinitionSpec) executionModeFromEnv() (ExecutionMode, bool) {
+	c := jds.Template.GetMainContainer()
+	if c == nil {
+		return "", false
+	}
+	for _, e := range c.Env {
+		if e.Name == CEExecutionMode {
+			if e.Value == CEExecutionModeValue {
+				return ExecutionModeDaemon, true
+			}
+			return ExecutionModeTask, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,101 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// legacySpec returns a spec which selects its execution mode by environment variable, like older clients.
+func legacySpec(value string) JobDefinitionSpec {
+	return JobDefinitionSpec{Template: JobPodTemplate{Containers: []corev1.Container{{
+		Name:  "main",
+		Image: "busybox",
+		Env:   []corev1.EnvVar{{Name: "GREETING", Value: "hello"}, {Name: CEExecutionMode, Value: value}},
+	}}}}
+}
+
+func TestExecutionModeRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		spec JobDefinitionSpec
+		want ExecutionMode
+	}{
+		{name: "daemon env", spec: legacySpec(CEExecutionModeValue), want: ExecutionModeDaemon},
+		{name: "task env", spec: legacySpec("TASK"), want: ExecutionModeTask},
+		{name: "daemon mode", spec: JobDefinitionSpec{ExecutionMode: ExecutionModeDaemon, Template: legacySpec("").Template}, want: ExecutionModeDaemon},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			spec := *test.spec.DeepCopy()
+			if test.spec.ExecutionMode != "" {
+				// The spec of a newer client, without the environment variable.
+				spec.Template.Containers[0].Env = spec.Template.Containers[0].Env[:1]
+			}
+			spec.ExecutionModeToEnv()
+			spec.ExecutionModeFromEnv()
+			if spec.ExecutionMode != test.want {
+				t.Errorf("executionMode = %q, want %q", spec.ExecutionMode, test.want)
+			}
+
+			// Translating back and forth again changes nothing.
+			again := *spec.DeepCopy()
+			again.ExecutionModeToEnv()
+			again.ExecutionModeFromEnv()
+			if !reflect.DeepEqual(again, spec) {
+				t.Errorf("the second round trip changed the spec to %+v, want %+v", again, spec)
+			}
+			if err := spec.Validate(context.Background()); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+			if got, _ := spec.executionModeFromEnv(); spec.IsDaemon() && got != ExecutionModeDaemon {
+				t.Errorf("the environment selects %q, want daemon mode for older servers", got)
+			}
+		})
+	}
+}
+
+func TestExecutionModeFromEnvKeepsEnv(t *testing.T) {
+	// A defaulted daemon jobRun created against an older server must stay a daemon.
+	jr := &JobRun{Spec: JobRunSpec{JobDefinitionSpec: legacySpec(CEExecutionModeValue)}}
+	want := jr.Spec.JobDefinitionSpec.Template.Containers[0].Env
+	jr.SetDefaults(context.Background())
+
+	if got := jr.Spec.JobDefinitionSpec.Template.Containers[0].Env; !reflect.DeepEqual(got, want) {
+		t.Errorf("env after SetDefaults = %v, want %v", got, want)
+	}
+	if !jr.IsRunningInDaemonMode() {
+		t.Error("the defaulted jobRun isn't in daemon mode")
+	}
+
+	// The same holds for the spec inherited from a jobDefinition.
+	jr = &JobRun{Spec: JobRunSpec{JobDefinitionRef: "def"}}
+	jd := JobDefinition{ObjectMeta: metav1.ObjectMeta{Name: "def"}, Spec: legacySpec(CEExecutionModeValue)}
+	SetDefaultsFromJobDefinition(jr, jd)
+	if got := jr.Spec.JobDefinitionSpec.Template.Containers[0].Env; !reflect.DeepEqual(got, want) {
+		t.Errorf("env after SetDefaultsFromJobDefinition = %v, want %v", got, want)
+	}
+	if jr.Spec.JobDefinitionSpec.ExecutionMode != ExecutionModeDaemon {
+		t.Errorf("executionMode = %q, want daemon", jr.Spec.JobDefinitionSpec.ExecutionMode)
+	}
+}
+
+func TestExecutionModeConflict(t *testing.T) {
+	spec := legacySpec(CEExecutionModeValue)
+	spec.ExecutionMode = ExecutionModeTask
+	spec.ExecutionModeFromEnv()
+	if err := spec.Validate(context.Background()); err == nil {
+		t.Error("Validate accepted CE_EXECUTION_MODE=DAEMON with executionMode task")
+	}
+}
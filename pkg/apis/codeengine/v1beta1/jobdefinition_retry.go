@@ -0,0 +1,188 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/config"
+)
+
+const (
+	// DefaultMaxRetryBackoffSeconds is the maxBackoffSeconds of a retryPolicy which doesn't set it.
+	DefaultMaxRetryBackoffSeconds int64 = 600
+	// MaxRetryBackoffSeconds is the largest backoffSeconds and maxBackoffSeconds of a retryPolicy, one day.
+	MaxRetryBackoffSeconds int64 = 24 * 60 * 60
+)
+
+const (
+	// RetryReasonNonRetryableExitCode is the reason an index is not retried
+	// because its main container exited with one of the nonRetryableExitCodes.
+	RetryReasonNonRetryableExitCode = "NonRetryableExitCode"
+	// RetryReasonRetryLimitExceeded is the reason an index is not retried
+	// because it was retried retryLimit times.
+	RetryReasonRetryLimitExceeded = "RetryLimitExceeded"
+	// RetryReasonRetryBudgetExhausted is the reason an index is not retried
+	// because the indices of the jobRun were retried retryLimit times in total.
+	RetryReasonRetryBudgetExhausted = "RetryBudgetExhausted"
+)
+
+// SetDefaults sets the defaults of unset fields of the RetryPolicy.
+func (p *RetryPolicy) SetDefaults() {
+	if p.Budget == "" {
+		p.Budget = RetryBudgetIndex
+	}
+	if p.MaxBackoffSeconds == 0 && p.BackoffSeconds > 0 {
+		p.MaxBackoffSeconds = DefaultMaxRetryBackoffSeconds
+		if p.BackoffSeconds > p.MaxBackoffSeconds {
+			p.MaxBackoffSeconds = p.BackoffSeconds
+		}
+	}
+}
+
+// GetRetryPolicy returns the retry policy of the spec with defaults set.
+func (jds *JobDefinitionSpec) GetRetryPolicy() RetryPolicy {
+	var p RetryPolicy
+	if jds.RetryPolicy != nil {
+		p = *jds.RetryPolicy.DeepCopy()
+	}
+	p.SetDefaults()
+	return p
+}
+
+// getRetryLimit returns the retryLimit of the spec or, if it's not set, the default SetDefaults
+// applies with the config attached to ctx.
+func (jds *JobDefinitionSpec) getRetryLimit(ctx context.Context) int64 {
+	if jds.RetryLimit != nil {
+		return *jds.RetryLimit
+	}
+	return config.FromContextOrDefaults(ctx).Defaults.RetryLimit
+}
+
+// Backoff returns the delay before the given retry of an index, counted from 1.
+// The delay saturates at MaxRetryBackoffSeconds, even if the policy wasn't validated.
+func (p *RetryPolicy) Backoff(retry int64) time.Duration {
+	delay, limit := p.BackoffSeconds, p.MaxBackoffSeconds
+	if delay <= 0 {
+		return 0
+	}
+	if delay > MaxRetryBackoffSeconds {
+		delay = MaxRetryBackoffSeconds
+	}
+	if limit > MaxRetryBackoffSeconds {
+		limit = MaxRetryBackoffSeconds
+	}
+	for i := int64(1); i < retry && delay < limit; i++ {
+		delay *= 2
+	}
+	if limit > 0 && delay > limit {
+		delay = limit
+	}
+	return time.Duration(delay) * time.Second
+}
+
+// IsRetryable returns false if the exit code is one of the nonRetryableExitCodes.
+func (p *RetryPolicy) IsRetryable(exitCode int32) bool {
+	for _, code := range p.NonRetryableExitCodes {
+		if code == exitCode {
+			return false
+		}
+	}
+	return true
+}
+
+// RetryHistory is the attempt history of a failed index, on which a retry is decided.
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type RetryHistory struct {
+	// Attempts is the number of attempts of the index, including the failed one.
+	Attempts int64
+	// ExitCode is the exit code of the main container in the failed attempt,
+	// nil if it didn't terminate, e.g. because the pod was evicted.
+	ExitCode *int32
+	// RunRetries is the number of retries of all indices of the jobRun so far.
+	// It's only considered by the RetryBudgetJobRun budget.
+	RunRetries int64
+}
+
+// RetryDecision is the decision whether and when a failed index is retried.
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type RetryDecision struct {
+	// Retry is true if the index is retried.
+	Retry bool
+	// Delay is the time to wait after the failed attempt before the retry.
+	Delay time.Duration
+	// NotBefore is the earliest time of the retry, zero if the finish time of the failed attempt is unknown.
+	NotBefore time.Time
+	// Reason is the reason the index is not retried, one of the RetryReason constants.
+	Reason string
+	// Message is a human readable explanation of Reason.
+	Message string
+}
+
+// DecideRetry decides whether and when a failed index is retried according to the retryPolicy and retryLimit.
+// Indices of jobs in daemon mode are restarted regardless of the retryLimit, but not after a non-retryable exit code.
+// An unset retryLimit defaults to the one of the config attached to ctx, see config.ToContext.
+func (jds *JobDefinitionSpec) DecideRetry(ctx context.Context, h RetryHistory) RetryDecision {
+	p := jds.GetRetryPolicy()
+	if h.ExitCode != nil && !p.IsRetryable(*h.ExitCode) {
+		return RetryDecision{
+			Reason:  RetryReasonNonRetryableExitCode,
+			Message: fmt.Sprintf("exit code %d is not retryable", *h.ExitCode),
+		}
+	}
+
+	retries := h.Attempts - 1
+	if retries < 0 {
+		retries = 0
+	}
+	if !jds.IsDaemon() {
+		limit := jds.getRetryLimit(ctx)
+		switch {
+		case p.Budget == RetryBudgetJobRun && h.RunRetries >= limit:
+			return RetryDecision{
+				Reason:  RetryReasonRetryBudgetExhausted,
+				Message: fmt.Sprintf("the indices of the jobRun were retried %d times, the retryLimit is %d", h.RunRetries, limit),
+			}
+		case p.Budget != RetryBudgetJobRun && retries >= limit:
+			return RetryDecision{
+				Reason:  RetryReasonRetryLimitExceeded,
+				Message: fmt.Sprintf("the index was retried %d times, the retryLimit is %d", retries, limit),
+			}
+		}
+	}
+	return RetryDecision{Retry: true, Delay: p.Backoff(retries + 1)}
+}
+
+// NextRetry decides whether and when the latest attempt of the index, which is expected to have failed, is retried.
+// See DecideRetry.
+// The history is taken from jr.Status.Indices, the retries of the jobRun are the ones of all its indices.
+// ok is false if nothing is known about the attempts of the index.
+func (jr *JobRun) NextRetry(ctx context.Context, idx int64) (decision RetryDecision, ok bool) {
+	status, ok := jr.GetIndexStatus(idx)
+	if !ok || status.Attempts == 0 {
+		return RetryDecision{}, false
+	}
+
+	h := RetryHistory{Attempts: status.Attempts, ExitCode: status.LastExitCode}
+	for _, s := range jr.Status.Indices {
+		if s.Attempts > 1 {
+			h.RunRetries += s.Attempts - 1
+		}
+	}
+
+	decision = jr.Spec.JobDefinitionSpec.DecideRetry(ctx, h)
+	if decision.Retry && status.FinishTime != nil {
+		decision.NotBefore = status.FinishTime.Add(decision.Delay)
+	}
+	return decision, true
+}
@@ -0,0 +1,63 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	day := time.Duration(MaxRetryBackoffSeconds) * time.Second
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int64
+		want   time.Duration
+	}{
+		{name: "first retry", policy: RetryPolicy{BackoffSeconds: 2, MaxBackoffSeconds: 60}, retry: 1, want: 2 * time.Second},
+		{name: "doubled", policy: RetryPolicy{BackoffSeconds: 2, MaxBackoffSeconds: 60}, retry: 4, want: 16 * time.Second},
+		{name: "capped", policy: RetryPolicy{BackoffSeconds: 2, MaxBackoffSeconds: 60}, retry: 6, want: time.Minute},
+		{name: "immediate", policy: RetryPolicy{MaxBackoffSeconds: 60}, retry: math.MaxInt64, want: 0},
+		{name: "largest valid policy", policy: RetryPolicy{BackoffSeconds: MaxRetryBackoffSeconds, MaxBackoffSeconds: MaxRetryBackoffSeconds}, retry: 100, want: day},
+		{name: "many retries", policy: RetryPolicy{BackoffSeconds: 1, MaxBackoffSeconds: MaxRetryBackoffSeconds}, retry: math.MaxInt64, want: day},
+		{name: "unvalidated max", policy: RetryPolicy{BackoffSeconds: 1, MaxBackoffSeconds: math.MaxInt64}, retry: 100, want: day},
+		{name: "unvalidated backoff", policy: RetryPolicy{BackoffSeconds: math.MaxInt64, MaxBackoffSeconds: math.MaxInt64}, retry: 2, want: day},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Backoff(tt.retry); got != tt.want {
+				t.Errorf("Backoff(%d) = %v, want %v", tt.retry, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyValidateBackoff(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   []string
+	}{
+		{name: "largest", policy: RetryPolicy{BackoffSeconds: MaxRetryBackoffSeconds, MaxBackoffSeconds: MaxRetryBackoffSeconds}},
+		{name: "negative", policy: RetryPolicy{BackoffSeconds: -1, MaxBackoffSeconds: -1}, want: []string{"backoffSeconds", "maxBackoffSeconds"}},
+		{name: "backoff too large", policy: RetryPolicy{BackoffSeconds: MaxRetryBackoffSeconds + 1}, want: []string{"backoffSeconds"}},
+		{name: "max too large", policy: RetryPolicy{BackoffSeconds: 1, MaxBackoffSeconds: math.MaxInt64}, want: []string{"maxBackoffSeconds"}},
+		{name: "max less than backoff", policy: RetryPolicy{BackoffSeconds: 10, MaxBackoffSeconds: 5}, want: []string{"maxBackoffSeconds"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorPaths(tt.policy.Validate()); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() paths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
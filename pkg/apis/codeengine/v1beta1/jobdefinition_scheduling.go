@@ -0,0 +1,248 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+// SpreadIndices returns a constraint which spreads the pods of the jobRun with the given name
+// evenly across the domains of topologyKey, e.g. corev1.LabelTopologyZone.
+// With corev1.ScheduleAnyway the constraint is a preference, with corev1.DoNotSchedule it's enforced.
+func SpreadIndices(jobRun, topologyKey string, whenUnsatisfiable corev1.UnsatisfiableConstraintAction) corev1.TopologySpreadConstraint {
+	return corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       topologyKey,
+		WhenUnsatisfiable: whenUnsatisfiable,
+		LabelSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{LabelJobRun: jobRun},
+		},
+	}
+}
+
+// IndexSpreadConstraints returns the topology spread constraints of the pods of the jobRun:
+// the ones of the template, which select the pods of the jobRun unless they set a labelSelector,
+// or else constraints which spread the indices evenly across zones and nodes where possible.
+func (jr *JobRun) IndexSpreadConstraints() []corev1.TopologySpreadConstraint {
+	template := &jr.Spec.JobDefinitionSpec.Template
+	if len(template.TopologySpreadConstraints) == 0 {
+		return []corev1.TopologySpreadConstraint{
+			SpreadIndices(jr.Name, corev1.LabelTopologyZone, corev1.ScheduleAnyway),
+			SpreadIndices(jr.Name, corev1.LabelHostname, corev1.ScheduleAnyway),
+		}
+	}
+
+	constraints := make([]corev1.TopologySpreadConstraint, 0, len(template.TopologySpreadConstraints))
+	for _, c := range template.TopologySpreadConstraints {
+		c = *c.DeepCopy()
+		if c.LabelSelector == nil {
+			c.LabelSelector = SpreadIndices(jr.Name, c.TopologyKey, c.WhenUnsatisfiable).LabelSelector
+		}
+		constraints = append(constraints, c)
+	}
+	return constraints
+}
+
+// validateScheduling validates the scheduling fields of the template, both on their own
+// and in combination: a nodeSelector which contradicts every required node affinity term,
+// or options of a spread constraint which don't apply to its whenUnsatisfiable, are rejected.
+func (t *JobPodTemplate) validateScheduling() *apis.FieldError {
+	var errs *apis.FieldError
+	for key, value := range t.NodeSelector {
+		for _, msg := range validation.IsQualifiedName(key) {
+			errs = errs.Also(apis.ErrInvalidKeyName(key, "nodeSelector", msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(value) {
+			errs = errs.Also(apis.ErrInvalidValue(value, apis.CurrentField, msg).ViaFieldKey("nodeSelector", key))
+		}
+	}
+
+	for i := range t.Tolerations {
+		errs = errs.Also(ValidateToleration(&t.Tolerations[i]).ViaFieldIndex("tolerations", i))
+	}
+
+	if t.Affinity != nil {
+		errs = errs.Also(ValidateAffinity(t.Affinity).ViaField("affinity"))
+		if na := t.Affinity.NodeAffinity; na != nil && na.RequiredDuringSchedulingIgnoredDuringExecution != nil && len(t.NodeSelector) > 0 {
+			terms := na.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			if len(terms) > 0 && !anyTermMatches(terms, t.NodeSelector) {
+				errs = errs.Also(apis.ErrGeneric("no node can match both nodeSelector and any of the required node selector terms",
+					"nodeSelector", "affinity.nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms"))
+			}
+		}
+	}
+
+	spreads := sets.NewString()
+	for i := range t.TopologySpreadConstraints {
+		c := &t.TopologySpreadConstraints[i]
+		key := c.TopologyKey + "/" + string(c.WhenUnsatisfiable)
+		if spreads.Has(key) {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("duplicate constraint for topologyKey %q and whenUnsatisfiable %q", c.TopologyKey, c.WhenUnsatisfiable),
+				"topologyKey", "whenUnsatisfiable").ViaFieldIndex("topologySpreadConstraints", i))
+		}
+		spreads.Insert(key)
+		errs = errs.Also(ValidateTopologySpreadConstraint(c).ViaFieldIndex("topologySpreadConstraints", i))
+	}
+	return errs
+}
+
+// ValidateToleration validates a toleration of the pod template.
+func ValidateToleration(t *corev1.Toleration) *apis.FieldError {
+	var errs *apis.FieldError
+	if t.Key != "" {
+		for _, msg := range validation.IsQualifiedName(t.Key) {
+			errs = errs.Also(apis.ErrInvalidValue(t.Key, "key", msg))
+		}
+	}
+
+	switch t.Operator {
+	case corev1.TolerationOpEqual, "":
+		if t.Key == "" {
+			errs = errs.Also(apis.ErrGeneric("an empty key requires operator Exists", "key", "operator"))
+		}
+	case corev1.TolerationOpExists:
+		if t.Value != "" {
+			errs = errs.Also(apis.ErrDisallowedFields("value"))
+		}
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(t.Operator, "operator", "must be Equal or Exists"))
+	}
+
+	switch t.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule:
+		if t.TolerationSeconds != nil {
+			errs = errs.Also(apis.ErrGeneric("tolerationSeconds requires effect NoExecute", "effect", "tolerationSeconds"))
+		}
+	case corev1.TaintEffectNoExecute:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(t.Effect, "effect", "must be NoSchedule, PreferNoSchedule or NoExecute"))
+	}
+	return errs
+}
+
+// ValidateAffinity validates the affinity of the pod template, which must not be nil.
+func ValidateAffinity(a *corev1.Affinity) *apis.FieldError {
+	var errs *apis.FieldError
+	if na := a.NodeAffinity; na != nil {
+		if required := na.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			if len(required.NodeSelectorTerms) == 0 {
+				errs = errs.Also(apis.ErrMissingField("nodeSelectorTerms").
+					ViaField("nodeAffinity", "requiredDuringSchedulingIgnoredDuringExecution"))
+			}
+		}
+		for i, term := range na.PreferredDuringSchedulingIgnoredDuringExecution {
+			errs = errs.Also(validateWeight(term.Weight).ViaFieldIndex("preferredDuringSchedulingIgnoredDuringExecution", i).ViaField("nodeAffinity"))
+		}
+	}
+
+	if pa := a.PodAffinity; pa != nil {
+		errs = errs.Also(validatePodAffinityTerms(pa.RequiredDuringSchedulingIgnoredDuringExecution,
+			pa.PreferredDuringSchedulingIgnoredDuringExecution).ViaField("podAffinity"))
+	}
+	if paa := a.PodAntiAffinity; paa != nil {
+		errs = errs.Also(validatePodAffinityTerms(paa.RequiredDuringSchedulingIgnoredDuringExecution,
+			paa.PreferredDuringSchedulingIgnoredDuringExecution).ViaField("podAntiAffinity"))
+	}
+	return errs
+}
+
+func validatePodAffinityTerms(required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm) *apis.FieldError {
+	var errs *apis.FieldError
+	for i := range required {
+		if required[i].TopologyKey == "" {
+			errs = errs.Also(apis.ErrMissingField("topologyKey").ViaFieldIndex("requiredDuringSchedulingIgnoredDuringExecution", i))
+		}
+	}
+	for i := range preferred {
+		errs = errs.Also(validateWeight(preferred[i].Weight).ViaFieldIndex("preferredDuringSchedulingIgnoredDuringExecution", i))
+		if preferred[i].PodAffinityTerm.TopologyKey == "" {
+			errs = errs.Also(apis.ErrMissingField("podAffinityTerm.topologyKey").ViaFieldIndex("preferredDuringSchedulingIgnoredDuringExecution", i))
+		}
+	}
+	return errs
+}
+
+func validateWeight(weight int32) *apis.FieldError {
+	if weight < 1 || weight > 100 {
+		return apis.ErrOutOfBoundsValue(weight, 1, 100, "weight")
+	}
+	return nil
+}
+
+// anyTermMatches returns true if a node labeled with nodeSelector could match one of the terms,
+// i.e. none of the match expressions of the term on a key of nodeSelector rules out its value.
+func anyTermMatches(terms []corev1.NodeSelectorTerm, nodeSelector map[string]string) bool {
+	for _, term := range terms {
+		if termMatches(term, nodeSelector) {
+			return true
+		}
+	}
+	return false
+}
+
+func termMatches(term corev1.NodeSelectorTerm, nodeSelector map[string]string) bool {
+	for _, expr := range term.MatchExpressions {
+		value, ok := nodeSelector[expr.Key]
+		if !ok {
+			continue
+		}
+		values := sets.NewString(expr.Values...)
+		switch expr.Operator {
+		case corev1.NodeSelectorOpIn:
+			if !values.Has(value) {
+				return false
+			}
+		case corev1.NodeSelectorOpNotIn:
+			if values.Has(value) {
+				return false
+			}
+		case corev1.NodeSelectorOpDoesNotExist:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateTopologySpreadConstraint validates a topology spread constraint of the pod template.
+func ValidateTopologySpreadConstraint(c *corev1.TopologySpreadConstraint) *apis.FieldError {
+	var errs *apis.FieldError
+	if c.MaxSkew <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(c.MaxSkew, "maxSkew", "must be greater than zero"))
+	}
+	if c.TopologyKey == "" {
+		errs = errs.Also(apis.ErrMissingField("topologyKey"))
+	} else {
+		for _, msg := range validation.IsQualifiedName(c.TopologyKey) {
+			errs = errs.Also(apis.ErrInvalidValue(c.TopologyKey, "topologyKey", msg))
+		}
+	}
+
+	switch c.WhenUnsatisfiable {
+	case corev1.DoNotSchedule:
+	case corev1.ScheduleAnyway:
+		// The scheduler only considers minDomains for constraints it enforces.
+		if c.MinDomains != nil {
+			errs = errs.Also(apis.ErrGeneric("minDomains requires whenUnsatisfiable DoNotSchedule", "minDomains", "whenUnsatisfiable"))
+		}
+	case "":
+		errs = errs.Also(apis.ErrMissingField("whenUnsatisfiable"))
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(c.WhenUnsatisfiable, "whenUnsatisfiable", "must be DoNotSchedule or ScheduleAnyway"))
+	}
+	if c.MinDomains != nil && *c.MinDomains <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(*c.MinDomains, "minDomains", "must be greater than zero"))
+	}
+	return errs
+}
@@ -0,0 +1,179 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestIndexSpreadConstraints(t *testing.T) {
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{LabelJobRun: "run"}}
+
+	jr := podJobRun()
+	want := []corev1.TopologySpreadConstraint{
+		{MaxSkew: 1, TopologyKey: corev1.LabelTopologyZone, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: selector},
+		{MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: selector},
+	}
+	if got := jr.IndexSpreadConstraints(); !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("default constraints = %+v, want %+v", got, want)
+	}
+
+	// Constraints of the template replace the defaults and select the pods of the jobRun unless they set a labelSelector.
+	own := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}}
+	jr.Spec.JobDefinitionSpec.Template.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+		{MaxSkew: 2, TopologyKey: corev1.LabelTopologyZone, WhenUnsatisfiable: corev1.DoNotSchedule},
+		{MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: own},
+	}
+	want = []corev1.TopologySpreadConstraint{
+		{MaxSkew: 2, TopologyKey: corev1.LabelTopologyZone, WhenUnsatisfiable: corev1.DoNotSchedule, LabelSelector: selector},
+		{MaxSkew: 1, TopologyKey: corev1.LabelHostname, WhenUnsatisfiable: corev1.ScheduleAnyway, LabelSelector: own},
+	}
+	if got := jr.IndexSpreadConstraints(); !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("constraints = %+v, want %+v", got, want)
+	}
+	if jr.Spec.JobDefinitionSpec.Template.TopologySpreadConstraints[0].LabelSelector != nil {
+		t.Error("IndexSpreadConstraints modified the template")
+	}
+
+	pod, err := jr.PodForIndex(0, PodOptions{})
+	if err != nil {
+		t.Fatalf("PodForIndex: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(pod.Spec.TopologySpreadConstraints, want) {
+		t.Errorf("the pod has the constraints %+v, want %+v", pod.Spec.TopologySpreadConstraints, want)
+	}
+}
+
+func TestJobDefinitionValidateScheduling(t *testing.T) {
+	withTemplate := func(fn func(t *JobPodTemplate)) *JobDefinition {
+		return validJobDefinition(func(jd *JobDefinition) { fn(&jd.Spec.Template) })
+	}
+	required := func(terms ...corev1.NodeSelectorTerm) *corev1.Affinity {
+		return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+		}}
+	}
+	term := func(key string, op corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorTerm {
+		return corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: key, Operator: op, Values: values}}}
+	}
+	spread := func(key string, when corev1.UnsatisfiableConstraintAction) corev1.TopologySpreadConstraint {
+		return corev1.TopologySpreadConstraint{MaxSkew: 1, TopologyKey: key, WhenUnsatisfiable: when}
+	}
+	amd64 := map[string]string{corev1.LabelArchStable: "amd64"}
+
+	tests := []struct {
+		name string
+		jd   *JobDefinition
+		want []string
+	}{{
+		name: "valid",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			t.NodeSelector = amd64
+			t.Tolerations = []corev1.Toleration{
+				{Key: "dedicated", Value: "batch", Effect: corev1.TaintEffectNoSchedule},
+				{Operator: corev1.TolerationOpExists},
+				{Key: "maintenance", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute, TolerationSeconds: pointer.Int64(60)},
+			}
+			t.Affinity = required(term(corev1.LabelArchStable, corev1.NodeSelectorOpIn, "arm64"), term(corev1.LabelArchStable, corev1.NodeSelectorOpIn, "amd64"))
+			t.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+				spread(corev1.LabelTopologyZone, corev1.DoNotSchedule),
+				spread(corev1.LabelTopologyZone, corev1.ScheduleAnyway),
+			}
+		}),
+	}, {
+		name: "invalid nodeSelector",
+		jd:   withTemplate(func(t *JobPodTemplate) { t.NodeSelector = map[string]string{"bad key!": "a", "arch": "bad value!"} }),
+		want: []string{"spec.template.nodeSelector", "spec.template.nodeSelector[arch]"},
+	}, {
+		name: "toleration with an empty key",
+		jd:   withTemplate(func(t *JobPodTemplate) { t.Tolerations = []corev1.Toleration{{Value: "batch"}} }),
+		want: []string{"spec.template.tolerations[0].key", "spec.template.tolerations[0].operator"},
+	}, {
+		name: "toleration of any value with a value",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			t.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists, Value: "batch"}}
+		}),
+		want: []string{"spec.template.tolerations[0].value"},
+	}, {
+		name: "tolerationSeconds without NoExecute",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			t.Tolerations = []corev1.Toleration{{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule, TolerationSeconds: pointer.Int64(60)}}
+		}),
+		want: []string{"spec.template.tolerations[0].effect", "spec.template.tolerations[0].tolerationSeconds"},
+	}, {
+		name: "required node affinity without terms",
+		jd:   withTemplate(func(t *JobPodTemplate) { t.Affinity = required() }),
+		want: []string{"spec.template.affinity.nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms"},
+	}, {
+		name: "preferred weight out of bounds",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			t.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+					Weight:          101,
+					PodAffinityTerm: corev1.PodAffinityTerm{TopologyKey: corev1.LabelHostname},
+				}},
+			}}
+		}),
+		want: []string{"spec.template.affinity.podAntiAffinity.preferredDuringSchedulingIgnoredDuringExecution[0].weight"},
+	}, {
+		name: "pod affinity without topologyKey",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			t.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{}},
+			}}
+		}),
+		want: []string{"spec.template.affinity.podAffinity.requiredDuringSchedulingIgnoredDuringExecution[0].topologyKey"},
+	}, {
+		name: "nodeSelector contradicting the required terms",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			t.NodeSelector = amd64
+			t.Affinity = required(term(corev1.LabelArchStable, corev1.NodeSelectorOpNotIn, "amd64"), term(corev1.LabelArchStable, corev1.NodeSelectorOpDoesNotExist))
+		}),
+		want: []string{"spec.template.affinity.nodeAffinity.requiredDuringSchedulingIgnoredDuringExecution.nodeSelectorTerms", "spec.template.nodeSelector"},
+	}, {
+		name: "duplicate spread constraints",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			t.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{
+				spread(corev1.LabelHostname, corev1.ScheduleAnyway),
+				spread(corev1.LabelHostname, corev1.ScheduleAnyway),
+			}
+		}),
+		want: []string{"spec.template.topologySpreadConstraints[1].topologyKey", "spec.template.topologySpreadConstraints[1].whenUnsatisfiable"},
+	}, {
+		name: "invalid spread constraint",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			t.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{TopologyKey: "bad key!"}}
+		}),
+		want: []string{"spec.template.topologySpreadConstraints[0].maxSkew", "spec.template.topologySpreadConstraints[0].topologyKey",
+			"spec.template.topologySpreadConstraints[0].whenUnsatisfiable"},
+	}, {
+		name: "minDomains of a preference",
+		jd: withTemplate(func(t *JobPodTemplate) {
+			c := spread(corev1.LabelTopologyZone, corev1.ScheduleAnyway)
+			c.MinDomains = pointer.Int32(2)
+			t.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{c}
+		}),
+		want: []string{"spec.template.topologySpreadConstraints[0].minDomains", "spec.template.topologySpreadConstraints[0].whenUnsatisfiable"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.jd.Validate(context.Background())
+			if got := errorPaths(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() reported %v, want %v: %v", got, tt.want, err)
+			}
+		})
+	}
+}
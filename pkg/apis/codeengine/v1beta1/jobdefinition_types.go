@@ -62,10 +62,76 @@ type JobDefinitionSpec struct {
 	// before the system tries to terminate it. Value must be positive integer
 	MaxExecutionTime *int64 `json:"maxExecutionTime,omitempty"`
 
+	// Specifies the maximum number of indices running at the same time.
+	// Further indices are held back until running ones finish and are reported as throttled.
+	// Value must be between 1 and 1000. All indices may run at the same time if not set.
+	// +optional
+	Parallelism *int64 `json:"parallelism,omitempty"`
+
+	// Specifies how failed indices are retried within the retryLimit.
+	// Failed indices are retried immediately, each up to retryLimit times, if not set.
+	// +optional
+	RetryPolicy *RetryPolicy `json:"retryPolicy,omitempty"`
+
+	// Specifies how the pods are run: "task" pods run to completion and are retried on failure,
+	// "daemon" pods are expected to run until the jobRun is deleted and are restarted whenever they exit.
+	// Default value is "task" if not set explicitly.
+	// +optional
+	ExecutionMode ExecutionMode `json:"executionMode,omitempty"`
+
 	// Specifies the template for creating copies of a pod
 	Template JobPodTemplate `json:"template,omitempty"`
 }
 
+// ExecutionMode is the mode the pods of a job are run in.
+// +enum
+type ExecutionMode string
+
+const (
+	// ExecutionModeTask runs the pods to completion.
+	ExecutionModeTask ExecutionMode = "task"
+	// ExecutionModeDaemon runs the pods until the jobRun is deleted.
+	ExecutionModeDaemon ExecutionMode = "daemon"
+)
+
+// RetryPolicy describes how failed indices are retried.
+type RetryPolicy struct {
+	// Delay in seconds before the first retry of an index, which doubles with every further retry
+	// of the index up to maxBackoffSeconds. Indices are retried immediately if not set.
+	// Must not exceed 86400, one day.
+	// +optional
+	BackoffSeconds int64 `json:"backoffSeconds,omitempty"`
+
+	// Upper bound in seconds of the delay between retries of an index.
+	// Default value is 600, or backoffSeconds if larger, if not set explicitly.
+	// Must not exceed 86400, one day.
+	// +optional
+	MaxBackoffSeconds int64 `json:"maxBackoffSeconds,omitempty"`
+
+	// Specifies what the retryLimit applies to: with "index" every index may be retried retryLimit times,
+	// with "jobRun" the indices of a jobRun share retryLimit retries.
+	// Default value is "index" if not set explicitly.
+	// +optional
+	Budget RetryBudget `json:"budget,omitempty"`
+
+	// Exit codes of the main container which mark an index failed without retrying it,
+	// e.g. for invalid input which fails every attempt.
+	// +optional
+	// +listType=set
+	NonRetryableExitCodes []int32 `json:"nonRetryableExitCodes,omitempty"`
+}
+
+// RetryBudget is what the retryLimit of a job applies to.
+// +enum
+type RetryBudget string
+
+const (
+	// RetryBudgetIndex allows every index retryLimit retries.
+	RetryBudgetIndex RetryBudget = "index"
+	// RetryBudgetJobRun allows the indices of a jobRun retryLimit retries in total.
+	RetryBudgetJobRun RetryBudget = "jobRun"
+)
+
 // JobDefinitionStatus is the current status of a jobDefinition resource
 type JobDefinitionStatus struct {
 	// Address holds the information needed for a Route to be the target of an event.
@@ -75,19 +141,65 @@ type JobDefinitionStatus struct {
 
 // JobPodTemplate is the template of pod resource
 type JobPodTemplate struct {
+	// List of initialization containers belonging to the pod.
+	// Init containers are run in order before the containers are started,
+	// the pod fails if any of them fails.
+	// +optional
+	// +listType=atomic
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
 	// List of containers belonging to the pod.
-	// Containers cannot currently be added or removed.
-	// There must be at least one container in a Pod.
-	// Cannot be updated.
+	// There must be at least one container in a Pod. The main container runs the workload
+	// of the job, any other container is a sidecar, e.g. a proxy or a log shipper.
+	// Containers must be named if there is more than one.
+	// +listType=atomic
 	Containers []corev1.Container `json:"containers,omitempty"`
 
+	// Name of the main container, whose exit determines the outcome of an index:
+	// the index succeeds if the main container exits with code 0 and fails otherwise,
+	// regardless of the sidecars.
+	// Defaults to the first container if not set explicitly.
+	// +optional
+	MainContainer string `json:"mainContainer,omitempty"`
+
 	// ImagePullSecrets is an optional list of references to secrets in the same namespace to use for pulling any of the images used by this PodSpec.
 	// If specified, these secrets will be passed to individual puller implementations for them to use. For example,
 	// in the case of docker, only DockerConfig type secrets are honored.
+	// +listType=atomic
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
 	// ServiceAccountName is an optional string of the service account name which the generated pods should belong to
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// List of volumes that can be mounted by the containers of the pod.
+	// Only secret, configMap, emptyDir and persistentVolumeClaim volumes are supported.
+	// Every volume mount of a container must refer to one of them by name.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
+	// NodeSelector is a selector which must match a node's labels for the pods to be scheduled on that node.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the pods, which allow them to be scheduled onto nodes with matching taints.
+	// +optional
+	// +listType=atomic
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity holds the node affinity and pod (anti-)affinity scheduling constraints of the pods.
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints describes how the pods of a jobRun are spread across topology domains,
+	// e.g. zones or nodes. A constraint without labelSelector applies to the pods of the jobRun.
+	// If no constraints are set, the indices are spread evenly across zones and nodes where possible.
+	// +optional
+	// +listType=map
+	// +listMapKey=topologyKey
+	// +listMapKey=whenUnsatisfiable
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,283 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+var _ apis.Validatable = (*JobDefinition)(nil)
+
+// Validate validates JobDefinition.
+func (jd *JobDefinition) Validate(ctx context.Context) *apis.FieldError {
+	errs := apis.ValidateObjectMetadata(jd.GetObjectMeta()).ViaField("metadata")
+	return errs.Also(jd.Spec.Validate(ctx).ViaField("spec"))
+}
+
+// Validate validates JobDefinitionSpec.
+// All fields are optional except for the template, which must define at least one container.
+func (jds *JobDefinitionSpec) Validate(ctx context.Context) *apis.FieldError {
+	errs := jds.validateFields(ctx)
+	return errs.Also(jds.Template.Validate(ctx).ViaField("template"))
+}
+
+// validateFields validates JobDefinitionSpec except for the template.
+func (jds *JobDefinitionSpec) validateFields(ctx context.Context) (errs *apis.FieldError) {
+	if jds.ArraySpec != nil {
+		errs = errs.Also(ValidateArraySpec(*jds.ArraySpec).ViaField("arraySpec"))
+	}
+
+	if jds.RetryLimit != nil && *jds.RetryLimit < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(*jds.RetryLimit, "retryLimit", "must not be negative"))
+	}
+
+	if jds.MaxExecutionTime != nil && *jds.MaxExecutionTime <= 0 {
+		errs = errs.Also(apis.ErrInvalidValue(*jds.MaxExecutionTime, "maxExecutionTime", "must be a positive integer"))
+	}
+
+	if jds.Parallelism != nil && (*jds.Parallelism < 1 || *jds.Parallelism > maxArraySize) {
+		errs = errs.Also(apis.ErrOutOfBoundsValue(*jds.Parallelism, 1, maxArraySize, "parallelism"))
+	}
+
+	if jds.RetryPolicy != nil {
+		errs = errs.Also(jds.RetryPolicy.Validate().ViaField("retryPolicy"))
+	}
+
+	errs = errs.Also(jds.validateExecutionMode())
+
+	return errs
+}
+
+// validateExecutionMode validates executionMode and its consistency with the
+// CE_EXECUTION_MODE environment variable of older clients.
+func (jds *JobDefinitionSpec) validateExecutionMode() *apis.FieldError {
+	switch jds.ExecutionMode {
+	case "":
+		return nil
+	case ExecutionModeTask, ExecutionModeDaemon:
+	default:
+		return apis.ErrInvalidValue(jds.ExecutionMode, "executionMode",
+			fmt.Sprintf("must be %q or %q", ExecutionModeTask, ExecutionModeDaemon))
+	}
+	if mode, ok := jds.executionModeFromEnv(); ok && mode != jds.ExecutionMode {
+		return apis.ErrGeneric(fmt.Sprintf("%s selects %s mode, which conflicts with executionMode %q", CEExecutionMode, mode, jds.ExecutionMode),
+			"executionMode", fmt.Sprintf("template.containers[%d].env", jds.Template.mainContainerIndex()))
+	}
+	return nil
+}
+
+// Validate validates RetryPolicy.
+func (p *RetryPolicy) Validate() *apis.FieldError {
+	var errs *apis.FieldError
+	switch {
+	case p.BackoffSeconds < 0:
+		errs = errs.Also(apis.ErrInvalidValue(p.BackoffSeconds, "backoffSeconds", "must not be negative"))
+	case p.BackoffSeconds > MaxRetryBackoffSeconds:
+		errs = errs.Also(apis.ErrOutOfBoundsValue(p.BackoffSeconds, 0, MaxRetryBackoffSeconds, "backoffSeconds"))
+	}
+	switch {
+	case p.MaxBackoffSeconds < 0:
+		errs = errs.Also(apis.ErrInvalidValue(p.MaxBackoffSeconds, "maxBackoffSeconds", "must not be negative"))
+	case p.MaxBackoffSeconds > MaxRetryBackoffSeconds:
+		errs = errs.Also(apis.ErrOutOfBoundsValue(p.MaxBackoffSeconds, 0, MaxRetryBackoffSeconds, "maxBackoffSeconds"))
+	case p.MaxBackoffSeconds > 0 && p.MaxBackoffSeconds < p.BackoffSeconds:
+		errs = errs.Also(apis.ErrInvalidValue(p.MaxBackoffSeconds, "maxBackoffSeconds", "must not be less than backoffSeconds"))
+	}
+
+	switch p.Budget {
+	case "", RetryBudgetIndex, RetryBudgetJobRun:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(p.Budget, "budget", fmt.Sprintf("must be %q or %q", RetryBudgetIndex, RetryBudgetJobRun)))
+	}
+
+	codes := sets.NewInt32()
+	for i, code := range p.NonRetryableExitCodes {
+		switch {
+		case code < 1 || code > 255:
+			errs = errs.Also(apis.ErrOutOfBoundsValue(code, 1, 255, apis.CurrentField).ViaFieldIndex("nonRetryableExitCodes", i))
+		case codes.Has(code):
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("duplicate exit code %d", code), apis.CurrentField).ViaFieldIndex("nonRetryableExitCodes", i))
+		}
+		codes.Insert(code)
+	}
+	return errs
+}
+
+// ValidateArraySpec validates the index notation of an arraySpec.
+// Indices must not exceed MaxIndexValue and at most maxArraySize indices may be specified.
+func ValidateArraySpec(arraySpec string) *apis.FieldError {
+	indices, err := ParseIndexSet(arraySpec)
+	if err != nil {
+		return apis.ErrInvalidValue(arraySpec, apis.CurrentField, err.Error())
+	}
+	if indices.IsEmpty() {
+		return apis.ErrInvalidValue(arraySpec, apis.CurrentField, "at least one index must be specified")
+	}
+
+	if count := indices.Count(); count > maxArraySize {
+		return apis.ErrInvalidValue(arraySpec, apis.CurrentField,
+			fmt.Sprintf("specifies %d indices, which is more than the maximum of %d", count, maxArraySize))
+	}
+	return nil
+}
+
+// Validate validates JobPodTemplate.
+func (t *JobPodTemplate) Validate(ctx context.Context) *apis.FieldError {
+	return t.validate(ctx, false)
+}
+
+// validate validates JobPodTemplate. A partial template, which is completed
+// from a referred jobDefinition, may omit containers and their images.
+func (t *JobPodTemplate) validate(ctx context.Context, partial bool) *apis.FieldError {
+	if len(t.Containers) == 0 && !partial {
+		return apis.ErrMissingField("containers")
+	}
+
+	var errs *apis.FieldError
+	names := sets.NewString()
+	validateContainer := func(field string, i int, c *corev1.Container, nameRequired bool) {
+		switch {
+		case c.Name != "" && names.Has(c.Name):
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("duplicate container name %q", c.Name), "name").ViaFieldIndex(field, i))
+		case c.Name == "" && nameRequired:
+			errs = errs.Also(apis.ErrMissingField("name").ViaFieldIndex(field, i))
+		}
+		names.Insert(c.Name)
+		if c.Image == "" && !partial {
+			errs = errs.Also(apis.ErrMissingField("image").ViaFieldIndex(field, i))
+		}
+	}
+	// Names identify the containers of a pod, a single container may be left for the server to name.
+	for i := range t.InitContainers {
+		validateContainer("initContainers", i, &t.InitContainers[i], true)
+	}
+	for i := range t.Containers {
+		validateContainer("containers", i, &t.Containers[i], len(t.Containers) > 1)
+	}
+
+	// A partial template may select a container inherited from the jobDefinition.
+	if t.MainContainer != "" && !partial && t.mainContainerIndex() < 0 {
+		errs = errs.Also(apis.ErrInvalidValue(t.MainContainer, "mainContainer", "must be the name of a container"))
+	}
+
+	for i, s := range t.ImagePullSecrets {
+		if s.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name").ViaFieldIndex("imagePullSecrets", i))
+		}
+	}
+
+	if t.ServiceAccountName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(t.ServiceAccountName) {
+			errs = errs.Also(apis.ErrInvalidValue(t.ServiceAccountName, "serviceAccountName", msg))
+		}
+	}
+
+	errs = errs.Also(t.validateScheduling())
+
+	volumes := sets.NewString()
+	for i := range t.Volumes {
+		v := &t.Volumes[i]
+		if volumes.Has(v.Name) {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("duplicate volume name %q", v.Name), "name").ViaFieldIndex("volumes", i))
+		}
+		volumes.Insert(v.Name)
+		errs = errs.Also(validateVolume(v).ViaFieldIndex("volumes", i))
+	}
+
+	// Mounts of a partial template may refer to volumes inherited from the jobDefinition.
+	for i := range t.InitContainers {
+		errs = errs.Also(validateVolumeMounts(t.InitContainers[i].VolumeMounts, volumes, partial).ViaFieldIndex("initContainers", i))
+	}
+	for i := range t.Containers {
+		errs = errs.Also(validateVolumeMounts(t.Containers[i].VolumeMounts, volumes, partial).ViaFieldIndex("containers", i))
+	}
+
+	return errs
+}
+
+// validateVolume validates the name of a volume and that it has exactly one of the supported sources.
+func validateVolume(v *corev1.Volume) *apis.FieldError {
+	var errs *apis.FieldError
+	if v.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("name"))
+	} else {
+		for _, msg := range validation.IsDNS1123Label(v.Name) {
+			errs = errs.Also(apis.ErrInvalidValue(v.Name, "name", msg))
+		}
+	}
+
+	var sources []string
+	if s := v.Secret; s != nil {
+		sources = append(sources, "secret")
+		if s.SecretName == "" {
+			errs = errs.Also(apis.ErrMissingField("secret.secretName"))
+		}
+	}
+	if c := v.ConfigMap; c != nil {
+		sources = append(sources, "configMap")
+		if c.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("configMap.name"))
+		}
+	}
+	if v.EmptyDir != nil {
+		sources = append(sources, "emptyDir")
+	}
+	if c := v.PersistentVolumeClaim; c != nil {
+		sources = append(sources, "persistentVolumeClaim")
+		if c.ClaimName == "" {
+			errs = errs.Also(apis.ErrMissingField("persistentVolumeClaim.claimName"))
+		}
+	}
+
+	// Any other source is left once the supported ones are unset.
+	other := v.VolumeSource
+	other.Secret, other.ConfigMap, other.EmptyDir, other.PersistentVolumeClaim = nil, nil, nil, nil
+	switch {
+	case !equality.Semantic.DeepEqual(other, corev1.VolumeSource{}):
+		errs = errs.Also(apis.ErrGeneric("only secret, configMap, emptyDir and persistentVolumeClaim volumes are supported", apis.CurrentField))
+	case len(sources) == 0:
+		errs = errs.Also(apis.ErrMissingOneOf("secret", "configMap", "emptyDir", "persistentVolumeClaim"))
+	case len(sources) > 1:
+		errs = errs.Also(apis.ErrMultipleOneOf(sources...))
+	}
+	return errs
+}
+
+// validateVolumeMounts validates that the mounts of a container refer to the given volumes,
+// unless the template is partial, and use distinct absolute paths.
+func validateVolumeMounts(mounts []corev1.VolumeMount, volumes sets.String, partial bool) *apis.FieldError {
+	var errs *apis.FieldError
+	paths := sets.NewString()
+	for i, m := range mounts {
+		if m.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name").ViaFieldIndex("volumeMounts", i))
+		} else if !partial && !volumes.Has(m.Name) {
+			errs = errs.Also(apis.ErrInvalidValue(m.Name, "name", "must be the name of a volume").ViaFieldIndex("volumeMounts", i))
+		}
+
+		switch {
+		case m.MountPath == "":
+			errs = errs.Also(apis.ErrMissingField("mountPath").ViaFieldIndex("volumeMounts", i))
+		case !path.IsAbs(m.MountPath):
+			errs = errs.Also(apis.ErrInvalidValue(m.MountPath, "mountPath", "must be an absolute path").ViaFieldIndex("volumeMounts", i))
+		case paths.Has(path.Clean(m.MountPath)):
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("duplicate mount path %q", m.MountPath), "mountPath").ViaFieldIndex("volumeMounts", i))
+		}
+		paths.Insert(path.Clean(m.MountPath))
+	}
+	return errs
+}
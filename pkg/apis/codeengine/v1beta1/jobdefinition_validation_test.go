@@ -0,0 +1,280 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+)
+
+// errorPaths returns the sorted paths of all errors, nil if there are none.
+func errorPaths(err *apis.FieldError) []string {
+	var paths []string
+	for _, e := range err.WrappedErrors() {
+		paths = append(paths, e.Paths...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// validJobDefinition returns a minimal valid jobDefinition, modified by fn.
+func validJobDefinition(fn func(jd *JobDefinition)) *JobDefinition {
+	jd := &JobDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "jd", Namespace: "ns"},
+		Spec: JobDefinitionSpec{
+			Template: JobPodTemplate{Containers: []corev1.Container{{Image: "icr.io/codeengine/helloworld"}}},
+		},
+	}
+	if fn != nil {
+		fn(jd)
+	}
+	return jd
+}
+
+func TestJobDefinitionValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		jd   *JobDefinition
+		want []string
+	}{{
+		name: "valid",
+		jd:   validJobDefinition(nil),
+	}, {
+		name: "invalid name",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Name = "1jd" }),
+		want: []string{"metadata.name"},
+	}, {
+		name: "arraySpec not in index notation",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.ArraySpec = pointer.String("1-x") }),
+		want: []string{"spec.arraySpec"},
+	}, {
+		name: "arraySpec without indices",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.ArraySpec = pointer.String("") }),
+		want: []string{"spec.arraySpec"},
+	}, {
+		name: "arraySpec with too many indices",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.ArraySpec = pointer.String("0-1000000") }),
+		want: []string{"spec.arraySpec"},
+	}, {
+		name: "arraySpec beyond MaxIndexValue",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.ArraySpec = pointer.String("10000000") }),
+		want: []string{"spec.arraySpec"},
+	}, {
+		name: "negative retryLimit",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.RetryLimit = pointer.Int64(-1) }),
+		want: []string{"spec.retryLimit"},
+	}, {
+		name: "zero maxExecutionTime",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.MaxExecutionTime = pointer.Int64(0) }),
+		want: []string{"spec.maxExecutionTime"},
+	}, {
+		name: "no containers",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.Template.Containers = nil }),
+		want: []string{"spec.template.containers"},
+	}, {
+		name: "container without image",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.Template.Containers[0].Image = "" }),
+		want: []string{"spec.template.containers[0].image"},
+	}, {
+		name: "duplicate container names",
+		jd: validJobDefinition(func(jd *JobDefinition) {
+			jd.Spec.Template.Containers = []corev1.Container{{Name: "c", Image: "a"}, {Name: "c", Image: "b"}}
+		}),
+		want: []string{"spec.template.containers[1].name"},
+	}, {
+		name: "imagePullSecret without name",
+		jd: validJobDefinition(func(jd *JobDefinition) {
+			jd.Spec.Template.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "s"}, {}}
+		}),
+		want: []string{"spec.template.imagePullSecrets[1].name"},
+	}, {
+		name: "invalid serviceAccountName",
+		jd:   validJobDefinition(func(jd *JobDefinition) { jd.Spec.Template.ServiceAccountName = "Default" }),
+		want: []string{"spec.template.serviceAccountName"},
+	}, {
+		name: "several errors",
+		jd: validJobDefinition(func(jd *JobDefinition) {
+			jd.Spec.RetryLimit = pointer.Int64(-1)
+			jd.Spec.Template.Containers[0].Image = ""
+		}),
+		want: []string{"spec.retryLimit", "spec.template.containers[0].image"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.jd.Validate(context.Background())
+			if got := errorPaths(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() reported %v, want %v: %v", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestJobDefinitionValidateContainers(t *testing.T) {
+	setup := corev1.Container{Name: "setup", Image: "setup"}
+	main := corev1.Container{Name: "main", Image: "main"}
+	proxy := corev1.Container{Name: "proxy", Image: "proxy"}
+	withTemplate := func(template JobPodTemplate) *JobDefinition {
+		return validJobDefinition(func(jd *JobDefinition) { jd.Spec.Template = template })
+	}
+
+	tests := []struct {
+		name string
+		jd   *JobDefinition
+		want []string
+	}{{
+		name: "init container and sidecar",
+		jd:   withTemplate(JobPodTemplate{InitContainers: []corev1.Container{setup}, Containers: []corev1.Container{main, proxy}, MainContainer: "main"}),
+	}, {
+		name: "sidecar first",
+		jd:   withTemplate(JobPodTemplate{Containers: []corev1.Container{proxy, main}, MainContainer: "main"}),
+	}, {
+		name: "unnamed init container",
+		jd:   withTemplate(JobPodTemplate{InitContainers: []corev1.Container{{Image: "setup"}}, Containers: []corev1.Container{{Image: "main"}}}),
+		want: []string{"spec.template.initContainers[0].name"},
+	}, {
+		name: "init container without image",
+		jd:   withTemplate(JobPodTemplate{InitContainers: []corev1.Container{{Name: "setup"}}, Containers: []corev1.Container{main}}),
+		want: []string{"spec.template.initContainers[0].image"},
+	}, {
+		name: "init container named like a container",
+		jd:   withTemplate(JobPodTemplate{InitContainers: []corev1.Container{{Name: "main", Image: "setup"}}, Containers: []corev1.Container{main}}),
+		want: []string{"spec.template.containers[0].name"},
+	}, {
+		name: "unnamed container next to a sidecar",
+		jd:   withTemplate(JobPodTemplate{Containers: []corev1.Container{{Image: "main"}, proxy}}),
+		want: []string{"spec.template.containers[0].name"},
+	}, {
+		name: "unknown mainContainer",
+		jd:   withTemplate(JobPodTemplate{Containers: []corev1.Container{main, proxy}, MainContainer: "setup"}),
+		want: []string{"spec.template.mainContainer"},
+	}, {
+		name: "mainContainer naming an init container",
+		jd:   withTemplate(JobPodTemplate{InitContainers: []corev1.Container{setup}, Containers: []corev1.Container{main}, MainContainer: "setup"}),
+		want: []string{"spec.template.mainContainer"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.jd.Validate(context.Background())
+			if got := errorPaths(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() reported %v, want %v: %v", got, tt.want, err)
+			}
+		})
+	}
+
+	// A jobRun may select the main container it inherits from its jobDefinition.
+	jr := &JobRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"},
+		Spec: JobRunSpec{JobDefinitionRef: "jd", JobDefinitionSpec: JobDefinitionSpec{
+			Template: JobPodTemplate{Containers: []corev1.Container{{Name: "proxy", Args: []string{"-v"}}}, MainContainer: "main"},
+		}},
+	}
+	if err := jr.Validate(context.Background()); err != nil {
+		t.Errorf("Validate() of a partial jobRun: %v", err)
+	}
+}
+
+func TestJobDefinitionValidateVolumes(t *testing.T) {
+	emptyDir := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	withVolumes := func(volumes []corev1.Volume, mounts ...corev1.VolumeMount) *JobDefinition {
+		return validJobDefinition(func(jd *JobDefinition) {
+			jd.Spec.Template.Volumes = volumes
+			jd.Spec.Template.Containers[0].VolumeMounts = mounts
+		})
+	}
+	data := []corev1.Volume{{Name: "data", VolumeSource: emptyDir}}
+
+	tests := []struct {
+		name string
+		jd   *JobDefinition
+		want []string
+	}{{
+		name: "supported sources",
+		jd: withVolumes([]corev1.Volume{
+			{Name: "secret", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "s"}}},
+			{Name: "config", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "c"}}}},
+			{Name: "scratch", VolumeSource: emptyDir},
+			{Name: "claim", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"}}},
+		}, corev1.VolumeMount{Name: "secret", MountPath: "/etc/secret"}, corev1.VolumeMount{Name: "claim", MountPath: "/data"}),
+	}, {
+		name: "volume without name",
+		jd:   withVolumes([]corev1.Volume{{VolumeSource: emptyDir}}),
+		want: []string{"spec.template.volumes[0].name"},
+	}, {
+		name: "invalid volume name",
+		jd:   withVolumes([]corev1.Volume{{Name: "Data", VolumeSource: emptyDir}}),
+		want: []string{"spec.template.volumes[0].name"},
+	}, {
+		name: "duplicate volume names",
+		jd:   withVolumes([]corev1.Volume{{Name: "data", VolumeSource: emptyDir}, {Name: "data", VolumeSource: emptyDir}}),
+		want: []string{"spec.template.volumes[1].name"},
+	}, {
+		name: "volume without source",
+		jd:   withVolumes([]corev1.Volume{{Name: "data"}}),
+		want: []string{"spec.template.volumes[0].configMap", "spec.template.volumes[0].emptyDir", "spec.template.volumes[0].persistentVolumeClaim", "spec.template.volumes[0].secret"},
+	}, {
+		name: "volume with two sources",
+		jd: withVolumes([]corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{}, Secret: &corev1.SecretVolumeSource{SecretName: "s"},
+		}}}),
+		want: []string{"spec.template.volumes[0].emptyDir", "spec.template.volumes[0].secret"},
+	}, {
+		name: "unsupported source",
+		jd: withVolumes([]corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{Path: "/var"},
+		}}}),
+		want: []string{"spec.template.volumes[0]"},
+	}, {
+		name: "secret without name",
+		jd:   withVolumes([]corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{}}}}),
+		want: []string{"spec.template.volumes[0].secret.secretName"},
+	}, {
+		name: "mount of an unknown volume",
+		jd:   withVolumes(data, corev1.VolumeMount{Name: "other", MountPath: "/data"}),
+		want: []string{"spec.template.containers[0].volumeMounts[0].name"},
+	}, {
+		name: "mount without name",
+		jd:   withVolumes(data, corev1.VolumeMount{MountPath: "/data"}),
+		want: []string{"spec.template.containers[0].volumeMounts[0].name"},
+	}, {
+		name: "relative mount path",
+		jd:   withVolumes(data, corev1.VolumeMount{Name: "data", MountPath: "data"}),
+		want: []string{"spec.template.containers[0].volumeMounts[0].mountPath"},
+	}, {
+		name: "mount without path",
+		jd:   withVolumes(data, corev1.VolumeMount{Name: "data"}),
+		want: []string{"spec.template.containers[0].volumeMounts[0].mountPath"},
+	}, {
+		name: "duplicate mount paths",
+		jd:   withVolumes(data, corev1.VolumeMount{Name: "data", MountPath: "/data"}, corev1.VolumeMount{Name: "data", MountPath: "/data/", SubPath: "x"}),
+		want: []string{"spec.template.containers[0].volumeMounts[1].mountPath"},
+	}, {
+		name: "mount in an init container",
+		jd: validJobDefinition(func(jd *JobDefinition) {
+			jd.Spec.Template.Volumes = data
+			jd.Spec.Template.InitContainers = []corev1.Container{{Name: "setup", Image: "setup",
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}, {Name: "cache", MountPath: "/cache"}}}}
+		}),
+		want: []string{"spec.template.initContainers[0].volumeMounts[1].name"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.jd.Validate(context.Background())
+			if got := errorPaths(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() reported %v, want %v: %v", got, tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// RetryTimes is the content of the AnnotationRetryTimes annotation: the number of retries
+// of each index, i.e. the number of pods created for it after the first one.
+// It's encoded as a JSON object with the decimal indices as keys, e.g. {"3":1,"17":2}.
+// Indices which were not retried are omitted.
+// These helpers are client-side only: the format is the one they write, the job controller
+// doesn't guarantee it, so ParseRetryTimes tolerates any other value.
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type RetryTimes map[int64]int64
+
+// ParseRetryTimes decodes the value of the AnnotationRetryTimes annotation.
+// Entries which aren't a valid index with a positive count are ignored,
+// and a value in an unknown format yields empty RetryTimes.
+func ParseRetryTimes(value string) RetryTimes {
+	times := RetryTimes{}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return times
+	}
+	for key, rawCount := range raw {
+		idx, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || idx < 0 {
+			continue
+		}
+		var count int64
+		if err := json.Unmarshal(rawCount, &count); err == nil && count > 0 {
+			times[idx] = count
+		}
+	}
+	return times
+}
+
+// String encodes the retry times as value of the AnnotationRetryTimes annotation.
+func (r RetryTimes) String() string {
+	raw := make(map[string]int64, len(r))
+	for idx, count := range r {
+		if count > 0 {
+			raw[strconv.FormatInt(idx, 10)] = count
+		}
+	}
+	// A map of strings to integers can always be marshaled.
+	data, _ := json.Marshal(raw)
+	return string(data)
+}
+
+// PodExpectations is the content of the AnnotationPodExpectations annotation: the number of pod
+// creations and deletions the job controller issued but has not observed yet.
+// It's encoded as a JSON object, e.g. {"add":2,"del":0}.
+// Like RetryTimes, the format is client-side only, so ParsePodExpectations tolerates any other value.
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type PodExpectations struct {
+	// Add is the number of pod creations to observe.
+	Add int64 `json:"add"`
+	// Del is the number of pod deletions to observe.
+	Del int64 `json:"del"`
+}
+
+// ParsePodExpectations decodes the value of the AnnotationPodExpectations annotation.
+// Counts which aren't a non-negative number are ignored, so a value in an unknown format
+// yields no expectations.
+func ParsePodExpectations(value string) PodExpectations {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		return PodExpectations{}
+	}
+	count := func(key string) int64 {
+		var n int64
+		if err := json.Unmarshal(raw[key], &n); err != nil || n < 0 {
+			return 0
+		}
+		return n
+	}
+	return PodExpectations{Add: count("add"), Del: count("del")}
+}
+
+// String encodes the expectations as value of the AnnotationPodExpectations annotation.
+func (e PodExpectations) String() string {
+	return fmt.Sprintf(`{"add":%d,"del":%d}`, e.Add, e.Del)
+}
+
+// Satisfied returns true if all pod creations and deletions were observed.
+func (e PodExpectations) Satisfied() bool {
+	return e.Add == 0 && e.Del == 0
+}
+
+// GetRetryTimes returns the retry times recorded in the AnnotationRetryTimes annotation,
+// see ParseRetryTimes.
+func (jr *JobRun) GetRetryTimes() RetryTimes {
+	return ParseRetryTimes(jr.Annotations[AnnotationRetryTimes])
+}
+
+// SetRetryTimes records the retry times in the AnnotationRetryTimes annotation,
+// which is removed if no index was retried.
+func (jr *JobRun) SetRetryTimes(times RetryTimes) {
+	value := times.String()
+	if value == "{}" {
+		delete(jr.Annotations, AnnotationRetryTimes)
+		return
+	}
+	if jr.Annotations == nil {
+		jr.Annotations = map[string]string{}
+	}
+	jr.Annotations[AnnotationRetryTimes] = value
+}
+
+// RecordRetry increments the retries of the index in the AnnotationRetryTimes annotation.
+// The annotation is rewritten in the format of RetryTimes, dropping the entries GetRetryTimes ignores.
+func (jr *JobRun) RecordRetry(idx int64) {
+	times := jr.GetRetryTimes()
+	times[idx]++
+	jr.SetRetryTimes(times)
+}
+
+// RetriesUsed returns the number of retries of the index.
+func (jr *JobRun) RetriesUsed(idx int64) int64 {
+	return jr.GetRetryTimes()[idx]
+}
+
+// UnlimitedRetries is the number of retries remaining in daemon mode, in which indices are
+// retried regardless of the retryLimit.
+const UnlimitedRetries int64 = math.MaxInt64
+
+// RetriesRemaining returns the number of retries left for the index before it's marked failed,
+// according to the retryLimit of the jobRun, or the default of the config attached to ctx if it's not set.
+// With the RetryBudgetJobRun budget of the retryPolicy, the retries of all indices count.
+// In daemon mode UnlimitedRetries is returned, see DecideRetry.
+func (jr *JobRun) RetriesRemaining(ctx context.Context, idx int64) int64 {
+	jds := &jr.Spec.JobDefinitionSpec
+	if jds.IsDaemon() {
+		return UnlimitedRetries
+	}
+	times := jr.GetRetryTimes()
+	used := times[idx]
+	if jds.GetRetryPolicy().Budget == RetryBudgetJobRun {
+		used = 0
+		for _, count := range times {
+			used += count
+		}
+	}
+	if limit := jds.getRetryLimit(ctx); used < limit {
+		return limit - used
+	}
+	return 0
+}
+
+// GetPodExpectations returns the expectations recorded in the AnnotationPodExpectations annotation,
+// see ParsePodExpectations.
+func (jr *JobRun) GetPodExpectations() PodExpectations {
+	return ParsePodExpectations(jr.Annotations[AnnotationPodExpectations])
+}
+
+// SetPodExpectations records the expectations in the AnnotationPodExpectations annotation,
+// which is removed once they are satisfied.
+func (jr *JobRun) SetPodExpectations(e PodExpectations) {
+	if e.Satisfied() {
+		delete(jr.Annotations, AnnotationPodExpectations)
+		return
+	}
+	if jr.Annotations == nil {
+		jr.Annotations = map[string]string{}
+	}
+	jr.Annotations[AnnotationPodExpectations] = e.String()
+}
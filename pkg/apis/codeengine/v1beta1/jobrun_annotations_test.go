@@ -0,0 +1,166 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/utils/pointer"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/config"
+)
+
+func TestRetryTimesRoundTrip(t *testing.T) {
+	tests := []struct {
+		times RetryTimes
+		want  string
+	}{
+		{times: RetryTimes{}, want: "{}"},
+		{times: RetryTimes{3: 1}, want: `{"3":1}`},
+		{times: RetryTimes{3: 1, 17: 2, 5: 0}, want: `{"17":2,"3":1}`},
+	}
+	for _, tt := range tests {
+		value := tt.times.String()
+		if value != tt.want {
+			t.Errorf("%v.String() = %s, want %s", tt.times, value, tt.want)
+		}
+		got := ParseRetryTimes(value)
+		want := RetryTimes{}
+		for idx, count := range tt.times {
+			if count > 0 {
+				want[idx] = count
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseRetryTimes(%s) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestParseRetryTimesUnknownFormat(t *testing.T) {
+	tests := []struct {
+		value string
+		want  RetryTimes
+	}{
+		{value: "", want: RetryTimes{}},
+		{value: "3:1", want: RetryTimes{}},
+		{value: `[1]`, want: RetryTimes{}},
+		{value: `{"3":"1"}`, want: RetryTimes{}},
+		{value: `{"x":1,"-1":1,"3":-1,"4":1.5}`, want: RetryTimes{}},
+		{value: `{"x":1,"3":1,"5":"2","17":2}`, want: RetryTimes{3: 1, 17: 2}},
+	}
+	for _, tt := range tests {
+		if got := ParseRetryTimes(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRetryTimes(%s) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestPodExpectationsRoundTrip(t *testing.T) {
+	for _, e := range []PodExpectations{{}, {Add: 2}, {Add: 1, Del: 3}} {
+		if got := ParsePodExpectations(e.String()); got != e {
+			t.Errorf("ParsePodExpectations(%s) = %+v, want %+v", e, got, e)
+		}
+	}
+	if got := (PodExpectations{Add: 2}).String(); got != `{"add":2,"del":0}` {
+		t.Errorf("String() = %s", got)
+	}
+}
+
+func TestParsePodExpectationsUnknownFormat(t *testing.T) {
+	tests := []struct {
+		value string
+		want  PodExpectations
+	}{
+		{value: "", want: PodExpectations{}},
+		{value: "2", want: PodExpectations{}},
+		{value: `{"add":"2"}`, want: PodExpectations{}},
+		{value: `{"add":-1,"del":2}`, want: PodExpectations{Del: 2}},
+		{value: `{"add":1,"del":-1,"mod":3}`, want: PodExpectations{Add: 1}},
+	}
+	for _, tt := range tests {
+		if got := ParsePodExpectations(tt.value); got != tt.want {
+			t.Errorf("ParsePodExpectations(%s) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAnnotationsRemovedWhenEmpty(t *testing.T) {
+	jr := &JobRun{}
+	jr.SetRetryTimes(RetryTimes{1: 1})
+	jr.SetPodExpectations(PodExpectations{Del: 1})
+	jr.SetRetryTimes(RetryTimes{})
+	jr.SetPodExpectations(PodExpectations{})
+	if len(jr.Annotations) != 0 {
+		t.Errorf("annotations = %v, want none", jr.Annotations)
+	}
+}
+
+func TestRetriesRemaining(t *testing.T) {
+	tests := []struct {
+		name string
+		spec JobDefinitionSpec
+		idx  int64
+		want int64
+	}{
+		{name: "index budget", spec: JobDefinitionSpec{RetryLimit: pointer.Int64(3)}, idx: 1, want: 2},
+		{name: "exhausted", spec: JobDefinitionSpec{RetryLimit: pointer.Int64(1)}, idx: 2, want: 0},
+		{name: "not retried", spec: JobDefinitionSpec{RetryLimit: pointer.Int64(3)}, idx: 7, want: 3},
+		{name: "jobRun budget", spec: JobDefinitionSpec{
+			RetryLimit:  pointer.Int64(5),
+			RetryPolicy: &RetryPolicy{Budget: RetryBudgetJobRun},
+		}, idx: 7, want: 2},
+		{name: "daemon", spec: JobDefinitionSpec{RetryLimit: pointer.Int64(1), ExecutionMode: ExecutionModeDaemon}, idx: 2, want: UnlimitedRetries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jr := &JobRun{}
+			jr.Spec.JobDefinitionSpec = tt.spec
+			jr.SetRetryTimes(RetryTimes{1: 1, 2: 2})
+			if got := jr.RetriesRemaining(context.Background(), tt.idx); got != tt.want {
+				t.Errorf("RetriesRemaining(%d) = %d, want %d", tt.idx, got, tt.want)
+			}
+		})
+	}
+
+	jr := &JobRun{}
+	jr.Spec.JobDefinitionSpec.RetryLimit = pointer.Int64(3)
+	jr.Annotations = map[string]string{AnnotationRetryTimes: "3"}
+	if got := jr.RetriesRemaining(context.Background(), 0); got != 3 {
+		t.Errorf("RetriesRemaining with an unknown annotation format = %d, want 3", got)
+	}
+	jr.RecordRetry(0)
+	if got := jr.Annotations[AnnotationRetryTimes]; got != `{"0":1}` {
+		t.Errorf("RecordRetry rewrote the annotation to %s, want {\"0\":1}", got)
+	}
+}
+
+func TestRetriesRemainingAgreesWithDefaults(t *testing.T) {
+	defaults, err := config.NewDefaultsConfigFromMap(map[string]string{"retry-limit": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := config.ToContext(context.Background(), &config.Config{Defaults: defaults})
+
+	jr := &JobRun{}
+	jr.SetRetryTimes(RetryTimes{1: 1})
+	got := jr.RetriesRemaining(ctx, 1)
+
+	defaulted := jr.DeepCopy()
+	defaulted.SetDefaults(ctx)
+	want := defaulted.RetriesRemaining(context.Background(), 1)
+	if got != 4 || got != want {
+		t.Errorf("RetriesRemaining = %d before and %d after SetDefaults, want 4", got, want)
+	}
+	if defaulted.Spec.JobDefinitionSpec.RetryPolicy != nil {
+		t.Errorf("SetDefaults set retryPolicy %+v, want it unset", defaulted.Spec.JobDefinitionSpec.RetryPolicy)
+	}
+}
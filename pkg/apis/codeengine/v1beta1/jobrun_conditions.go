@@ -0,0 +1,180 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/clock"
+	"knative.dev/pkg/apis"
+)
+
+// jobRunCondSet is the set of jobRun conditions, its top-level condition is Succeeded.
+var jobRunCondSet = apis.NewBatchConditionSet()
+
+var _ apis.ConditionsAccessor = (*JobRunStatus)(nil)
+
+// GetConditionSet returns the set of conditions of JobRun.
+func (*JobRun) GetConditionSet() apis.ConditionSet {
+	return jobRunCondSet
+}
+
+// IsStale returns true if the status doesn't reflect the latest generation of the jobRun.
+// Servers which don't report observedGeneration are never stale.
+func (j *JobRun) IsStale() bool {
+	return j.Status.ObservedGeneration != 0 && j.Status.ObservedGeneration < j.Generation
+}
+
+// GetConditions implements apis.ConditionsAccessor.
+func (s *JobRunStatus) GetConditions() apis.Conditions {
+	return jobRunConditionsAccessor{status: s}.GetConditions()
+}
+
+// SetConditions implements apis.ConditionsAccessor.
+// Transitions are stamped with the current time.
+func (s *JobRunStatus) SetConditions(conditions apis.Conditions) {
+	jobRunConditionsAccessor{status: s, clock: clock.RealClock{}}.SetConditions(conditions)
+}
+
+// ManageConditions returns a manager of the conditions of the status, which stamps
+// transitions with the time of clk, e.g. a fake clock in tests.
+func (s *JobRunStatus) ManageConditions(clk clock.PassiveClock) *JobRunConditionManager {
+	return &JobRunConditionManager{
+		ConditionManager: jobRunCondSet.Manage(jobRunConditionsAccessor{status: s, clock: clk}),
+		status:           s,
+	}
+}
+
+// JobRunConditionManager maintains the phase conditions Pending, Running, Complete and Failed
+// of a jobRun together with its top-level Succeeded condition.
+// The reason of Succeeded is the phase of the jobRun, or the reason of its failure.
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type JobRunConditionManager struct {
+	apis.ConditionManager
+	status *JobRunStatus
+}
+
+// MarkPending marks the jobRun as submitted into the system.
+func (m *JobRunConditionManager) MarkPending() {
+	m.setPhaseCondition(JobPending, "", "")
+	m.MarkUnknown(apis.ConditionSucceeded, string(JobPending), "")
+}
+
+// MarkRunning marks the jobRun as running at least one pod.
+func (m *JobRunConditionManager) MarkRunning() {
+	m.setPhaseCondition(JobRunning, "", "")
+	m.MarkUnknown(apis.ConditionSucceeded, string(JobRunning), "")
+}
+
+// MarkComplete marks the jobRun as having completed its execution.
+func (m *JobRunConditionManager) MarkComplete() {
+	m.setPhaseCondition(JobComplete, "", "")
+	m.MarkTrueWithReason(apis.ConditionSucceeded, string(JobComplete), "")
+}
+
+// MarkFailed marks the jobRun as having failed its execution.
+func (m *JobRunConditionManager) MarkFailed(reason, message string) {
+	m.setPhaseCondition(JobFailed, reason, message)
+	m.MarkFalse(apis.ConditionSucceeded, reason, "%s", message)
+}
+
+// MarkObservedGeneration records that the status reflects the given generation of the jobRun.
+func (m *JobRunConditionManager) MarkObservedGeneration(generation int64) {
+	m.status.ObservedGeneration = generation
+}
+
+// setPhaseCondition sets a phase condition to true.
+// It doesn't use MarkTrue, which would mark the top-level condition true as well.
+func (m *JobRunConditionManager) setPhaseCondition(t JobRunConditionType, reason, message string) {
+	m.SetCondition(apis.Condition{
+		Type:     apis.ConditionType(t),
+		Status:   corev1.ConditionTrue,
+		Severity: conditionSeverity(apis.ConditionType(t)),
+		Reason:   reason,
+		Message:  message,
+	})
+}
+
+// jobRunConditionsAccessor translates between JobRunCondition and apis.Condition.
+// Like AddCondition it keeps the order of the conditions and puts changed conditions last,
+// so GetLatestCondition returns the latest transition.
+// +k8s:deepcopy-gen=false
+// +k8s:openapi-gen=false
+type jobRunConditionsAccessor struct {
+	status *JobRunStatus
+	clock  clock.PassiveClock
+}
+
+func (a jobRunConditionsAccessor) GetConditions() apis.Conditions {
+	if len(a.status.Conditions) == 0 {
+		return nil
+	}
+	conditions := make(apis.Conditions, 0, len(a.status.Conditions))
+	for _, c := range a.status.Conditions {
+		t := apis.ConditionType(c.Type)
+		conditions = append(conditions, apis.Condition{
+			Type:               t,
+			Status:             c.Status,
+			Severity:           conditionSeverity(t),
+			LastTransitionTime: apis.VolatileTime{Inner: c.LastTransitionTime},
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+	return conditions
+}
+
+func (a jobRunConditionsAccessor) SetConditions(conditions apis.Conditions) {
+	updated := make(map[JobRunConditionType]apis.Condition, len(conditions))
+	for _, c := range conditions {
+		updated[JobRunConditionType(c.Type)] = c
+	}
+
+	var result, changed []JobRunCondition
+	unchanged := map[JobRunConditionType]bool{}
+	for _, old := range a.status.Conditions {
+		c, ok := updated[old.Type]
+		if !ok {
+			// The condition was cleared.
+			continue
+		}
+		if c.Status == old.Status && c.Reason == old.Reason && c.Message == old.Message &&
+			c.LastTransitionTime.Inner.Equal(&old.LastTransitionTime) {
+			result = append(result, old)
+			unchanged[old.Type] = true
+		}
+	}
+
+	now := metav1.NewTime(a.clock.Now())
+	for _, c := range conditions {
+		t := JobRunConditionType(c.Type)
+		if unchanged[t] {
+			continue
+		}
+		changed = append(changed, JobRunCondition{
+			Type:               t,
+			Status:             c.Status,
+			LastProbeTime:      now,
+			LastTransitionTime: now,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		})
+	}
+
+	a.status.Conditions = append(result, changed...)
+}
+
+// conditionSeverity returns the severity the condition set assigns to a condition type.
+func conditionSeverity(t apis.ConditionType) apis.ConditionSeverity {
+	if t == jobRunCondSet.GetTopLevelConditionType() {
+		return apis.ConditionSeverityError
+	}
+	return apis.ConditionSeverityInfo
+}
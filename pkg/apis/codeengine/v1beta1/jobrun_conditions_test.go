@@ -0,0 +1,181 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+	"knative.dev/pkg/apis"
+)
+
+var conditionsEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestConditionsRoundTrip(t *testing.T) {
+	created := metav1.NewTime(conditionsEpoch)
+	probed := metav1.NewTime(conditionsEpoch.Add(time.Hour))
+	status := &JobRunStatus{Conditions: []JobRunCondition{
+		{Type: JobPending, Status: corev1.ConditionTrue, LastProbeTime: probed, LastTransitionTime: created},
+		{Type: JobFailed, Status: corev1.ConditionTrue, LastProbeTime: probed, LastTransitionTime: created, Reason: "IndexFailed", Message: "index 3 failed"},
+		{Type: JobRunConditionType(apis.ConditionSucceeded), Status: corev1.ConditionFalse, LastTransitionTime: created, Reason: "IndexFailed", Message: "index 3 failed"},
+	}}
+	want := status.DeepCopy().Conditions
+
+	conditions := status.GetConditions()
+	wantConditions := apis.Conditions{
+		{Type: apis.ConditionType(JobPending), Status: corev1.ConditionTrue, Severity: apis.ConditionSeverityInfo,
+			LastTransitionTime: apis.VolatileTime{Inner: created}},
+		{Type: apis.ConditionType(JobFailed), Status: corev1.ConditionTrue, Severity: apis.ConditionSeverityInfo,
+			LastTransitionTime: apis.VolatileTime{Inner: created}, Reason: "IndexFailed", Message: "index 3 failed"},
+		{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse, Severity: apis.ConditionSeverityError,
+			LastTransitionTime: apis.VolatileTime{Inner: created}, Reason: "IndexFailed", Message: "index 3 failed"},
+	}
+	if !reflect.DeepEqual(conditions, wantConditions) {
+		t.Errorf("GetConditions() = %+v, want %+v", conditions, wantConditions)
+	}
+
+	// Unchanged conditions keep their order and timestamps, including the probe times.
+	status.SetConditions(conditions)
+	if !reflect.DeepEqual(status.Conditions, want) {
+		t.Errorf("conditions after the round trip = %+v, want %+v", status.Conditions, want)
+	}
+
+	// Cleared conditions are removed.
+	status.SetConditions(conditions[1:])
+	if !reflect.DeepEqual(status.Conditions, want[1:]) {
+		t.Errorf("conditions after clearing Pending = %+v, want %+v", status.Conditions, want[1:])
+	}
+
+	if (&JobRunStatus{}).GetConditions() != nil {
+		t.Error("GetConditions() of an empty status isn't nil")
+	}
+}
+
+func TestManageConditionsClock(t *testing.T) {
+	clk := clocktesting.NewFakePassiveClock(conditionsEpoch)
+	status := &JobRunStatus{}
+	m := status.ManageConditions(clk)
+
+	m.MarkPending()
+	for _, c := range status.Conditions {
+		if !c.LastTransitionTime.Time.Equal(conditionsEpoch) || !c.LastProbeTime.Time.Equal(conditionsEpoch) {
+			t.Errorf("condition %s transitioned at %s, want the time of the clock %s", c.Type, c.LastTransitionTime, conditionsEpoch)
+		}
+	}
+
+	later := conditionsEpoch.Add(time.Minute)
+	clk.SetTime(later)
+	m.MarkRunning()
+
+	if c := status.GetCondition(JobPending); c == nil || !c.LastTransitionTime.Time.Equal(conditionsEpoch) {
+		t.Errorf("the unchanged Pending condition is %+v, want it to keep its transition time", c)
+	}
+	if c := status.GetCondition(JobRunning); c == nil || !c.LastTransitionTime.Time.Equal(later) {
+		t.Errorf("the Running condition is %+v, want a transition at %s", c, later)
+	}
+	if c := status.GetLatestCondition(); c == nil || !c.LastTransitionTime.Time.Equal(later) {
+		t.Errorf("the latest condition is %+v, want a transition at %s", c, later)
+	}
+}
+
+func TestSucceededReasons(t *testing.T) {
+	tests := []struct {
+		name       string
+		mark       func(m *JobRunConditionManager)
+		wantStatus corev1.ConditionStatus
+		wantReason string
+		wantMsg    string
+	}{{
+		name:       "pending",
+		mark:       func(m *JobRunConditionManager) { m.MarkPending() },
+		wantStatus: corev1.ConditionUnknown,
+		wantReason: "Pending",
+	}, {
+		name:       "running",
+		mark:       func(m *JobRunConditionManager) { m.MarkRunning() },
+		wantStatus: corev1.ConditionUnknown,
+		wantReason: "Running",
+	}, {
+		name:       "complete",
+		mark:       func(m *JobRunConditionManager) { m.MarkComplete() },
+		wantStatus: corev1.ConditionTrue,
+		wantReason: "Complete",
+	}, {
+		name:       "failed",
+		mark:       func(m *JobRunConditionManager) { m.MarkFailed("MaxExecutionTimeExceeded", "ran for more than 60s") },
+		wantStatus: corev1.ConditionFalse,
+		wantReason: "MaxExecutionTimeExceeded",
+		wantMsg:    "ran for more than 60s",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			status := &JobRunStatus{}
+			m := status.ManageConditions(clocktesting.NewFakePassiveClock(conditionsEpoch))
+			m.MarkPending()
+			test.mark(m)
+
+			c := status.GetCondition(JobRunConditionType(apis.ConditionSucceeded))
+			if c == nil || c.Status != test.wantStatus || c.Reason != test.wantReason || c.Message != test.wantMsg {
+				t.Errorf("Succeeded = %+v, want %s with reason %q and message %q", c, test.wantStatus, test.wantReason, test.wantMsg)
+			}
+		})
+	}
+}
+
+func TestPhasePriority(t *testing.T) {
+	condition := func(t JobRunConditionType, status corev1.ConditionStatus) JobRunCondition {
+		return JobRunCondition{Type: t, Status: status}
+	}
+	tests := []struct {
+		name       string
+		conditions []JobRunCondition
+		want       JobRunPhase
+	}{{
+		name:       "none",
+		conditions: nil,
+		want:       JobRunPhaseUnknown,
+	}, {
+		name:       "running after pending",
+		conditions: []JobRunCondition{condition(JobPending, corev1.ConditionTrue), condition(JobRunning, corev1.ConditionTrue)},
+		want:       JobRunPhaseRunning,
+	}, {
+		name:       "running before pending",
+		conditions: []JobRunCondition{condition(JobRunning, corev1.ConditionTrue), condition(JobPending, corev1.ConditionTrue)},
+		want:       JobRunPhaseRunning,
+	}, {
+		name: "failed before complete",
+		conditions: []JobRunCondition{condition(JobFailed, corev1.ConditionTrue), condition(JobComplete, corev1.ConditionTrue),
+			condition(JobRunning, corev1.ConditionTrue)},
+		want: JobRunPhaseFailed,
+	}, {
+		name:       "complete before failed",
+		conditions: []JobRunCondition{condition(JobComplete, corev1.ConditionTrue), condition(JobFailed, corev1.ConditionTrue)},
+		want:       JobRunPhaseFailed,
+	}, {
+		name:       "false conditions",
+		conditions: []JobRunCondition{condition(JobPending, corev1.ConditionTrue), condition(JobFailed, corev1.ConditionFalse), condition(JobRunning, corev1.ConditionUnknown)},
+		want:       JobRunPhasePending,
+	}, {
+		name:       "only the top-level condition",
+		conditions: []JobRunCondition{condition(JobRunConditionType(apis.ConditionSucceeded), corev1.ConditionTrue)},
+		want:       JobRunPhaseUnknown,
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jr := &JobRun{Status: JobRunStatus{Conditions: test.conditions}}
+			if got := jr.Phase(); got != test.want {
+				t.Errorf("Phase() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -9,31 +9,61 @@
 package v1beta1
 
 import (
+	"context"
+
 	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/config"
 )
 
+var _ apis.Defaultable = (*JobRun)(nil)
+
+func init() {
+	// The default arraySpec of the config map is validated when it's loaded, not once it's applied.
+	config.ArraySpecValidator = func(arraySpec string) error {
+		if err := ValidateArraySpec(arraySpec); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
 // SetDefaults sets defaults for JobRun.
-func (jr *JobRun) SetDefaults() {
-	jr.Spec.SetDefaults()
+// Defaults are read from the config attached to ctx, see config.ToContext,
+// and fall back to the built-in defaults of the config package.
+func (jr *JobRun) SetDefaults(ctx context.Context) {
+	jr.Spec.SetDefaults(ctx)
 }
 
 // SetDefaults sets defaults for JobRun Spec.
-func (js *JobRunSpec) SetDefaults() {
+// The CE_EXECUTION_MODE environment variable of older clients is translated into executionMode.
+func (js *JobRunSpec) SetDefaults(ctx context.Context) {
+	js.JobDefinitionSpec.ExecutionModeFromEnv()
+
 	// Set defaults for standalone jobRun only.
 	if js.JobDefinitionRef != "" {
 		return
 	}
 
+	defaults := config.FromContextOrDefaults(ctx).Defaults
+
 	if js.JobDefinitionSpec.ArraySpec == nil {
-		js.JobDefinitionSpec.ArraySpec = pointer.String("0")
+		js.JobDefinitionSpec.ArraySpec = pointer.String(defaults.ArraySpec)
 	}
 
 	if js.JobDefinitionSpec.RetryLimit == nil {
-		js.JobDefinitionSpec.RetryLimit = pointer.Int64(3)
+		js.JobDefinitionSpec.RetryLimit = pointer.Int64(defaults.RetryLimit)
 	}
 
 	if js.JobDefinitionSpec.MaxExecutionTime == nil {
-		js.JobDefinitionSpec.MaxExecutionTime = pointer.Int64(7200)
+		js.JobDefinitionSpec.MaxExecutionTime = pointer.Int64(defaults.MaxExecutionTime)
+	}
+
+	// retryPolicy stays unset, GetRetryPolicy applies its defaults.
+
+	if js.JobDefinitionSpec.ExecutionMode == "" {
+		js.JobDefinitionSpec.ExecutionMode = ExecutionModeTask
 	}
 }
 
@@ -43,18 +73,13 @@ func (js *JobRunSpec) RequiresDefaultingFromJobDefinition() bool {
 }
 
 // SetDefaultsFromJobDefinition set defaults in place from its JobDefinitionRef:
-// - Labels
-// - Container name and image
+//   - Labels
+//   - JobDefinitionSpec, as merged by MergeJobDefinitionSpec, with the execution mode of an older jobDefinition
+//     translated from its CE_EXECUTION_MODE environment variable
 func SetDefaultsFromJobDefinition(jr *JobRun, referredJD JobDefinition) {
 	jr.AddLabel(LabelJobDefName, jr.Spec.JobDefinitionRef, false)
 	jr.AddLabel(LabelJobDefUUID, string(referredJD.UID), false)
 
-	if len(referredJD.Spec.Template.Containers) == 1 && len(jr.Spec.JobDefinitionSpec.Template.Containers) == 1 {
-		if referredJD.Spec.Template.Containers[0].Name != "" && jr.Spec.JobDefinitionSpec.Template.Containers[0].Name == "" {
-			jr.Spec.JobDefinitionSpec.Template.Containers[0].Name = referredJD.Spec.Template.Containers[0].Name
-		}
-		if referredJD.Spec.Template.Containers[0].Image != "" && jr.Spec.JobDefinitionSpec.Template.Containers[0].Image == "" {
-			jr.Spec.JobDefinitionSpec.Template.Containers[0].Image = referredJD.Spec.Template.Containers[0].Image
-		}
-	}
+	jr.Spec.JobDefinitionSpec = MergeJobDefinitionSpec(&referredJD.Spec, &jr.Spec.JobDefinitionSpec)
+	jr.Spec.JobDefinitionSpec.ExecutionModeFromEnv()
 }
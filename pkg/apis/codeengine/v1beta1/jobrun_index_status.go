@@ -0,0 +1,96 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GetIndexStatus returns the status of the given index.
+// Indices without an entry in jr.Status.Indices are derived from jr.Status.SucceededIndices
+// and jr.Status.FailedIndices, so servers which only fill those are supported as well.
+// ok is false if nothing is known about the index.
+func (j *JobRun) GetIndexStatus(idx int64) (status IndexStatus, ok bool) {
+	indices := j.Status.Indices
+	i := sort.Search(len(indices), func(i int) bool { return indices[i].Index >= idx })
+	if i < len(indices) && indices[i].Index == idx {
+		return *indices[i].DeepCopy(), true
+	}
+
+	if succeeded, err := j.Status.GetSucceededIndexSet(); err == nil && succeeded.Contains(idx) {
+		return IndexStatus{Index: idx, Phase: corev1.PodSucceeded}, true
+	}
+	if failed, err := j.Status.GetFailedIndexSet(); err == nil && failed.Contains(idx) {
+		return IndexStatus{Index: idx, Phase: corev1.PodFailed}, true
+	}
+	return IndexStatus{Index: idx}, false
+}
+
+// SetIndexStatus sets or replaces the status of an index in jr.Status.Indices, keeping them sorted.
+func (j *JobRun) SetIndexStatus(status IndexStatus) {
+	indices := j.Status.Indices
+	i := sort.Search(len(indices), func(i int) bool { return indices[i].Index >= status.Index })
+	if i < len(indices) && indices[i].Index == status.Index {
+		indices[i] = status
+		return
+	}
+	indices = append(indices, IndexStatus{})
+	copy(indices[i+1:], indices[i:])
+	indices[i] = status
+	j.Status.Indices = indices
+}
+
+// GetIndicesInPhase returns the indices whose status, see GetIndexStatus, is in the given phase.
+func (j *JobRun) GetIndicesInPhase(phase corev1.PodPhase) IndexSet {
+	var set IndexSet
+	switch phase {
+	case corev1.PodSucceeded:
+		set, _ = j.Status.GetSucceededIndexSet()
+	case corev1.PodFailed:
+		set, _ = j.Status.GetFailedIndexSet()
+	}
+
+	var matching, other []int64
+	for _, status := range j.Status.Indices {
+		if status.Phase == phase {
+			matching = append(matching, status.Index)
+		} else {
+			other = append(other, status.Index)
+		}
+	}
+	// Entries of jr.Status.Indices take precedence over the index ranges.
+	return set.Difference(NewIndexSet(other...)).Union(NewIndexSet(matching...))
+}
+
+// UpdateIndexStatus records a pod of the index in jr.Status.Indices.
+// The attempt counts from 0 and the pod is expected to be the latest one of the index.
+// The phase, exit code and reason of the index are the ones of the main container, see IndexPhase.
+func (j *JobRun) UpdateIndexStatus(idx, attempt int64, pod *corev1.Pod) {
+	status, _ := j.GetIndexStatus(idx)
+	status.Phase = j.IndexPhase(pod)
+	if attempt+1 > status.Attempts {
+		status.Attempts = attempt + 1
+	}
+	if status.StartTime == nil && pod.Status.StartTime != nil {
+		status.StartTime = pod.Status.StartTime.DeepCopy()
+	}
+
+	if t := terminationOfIndex(pod, j.Spec.JobDefinitionSpec.Template.MainContainer); t != nil {
+		exitCode := t.ExitCode
+		status.LastExitCode = &exitCode
+		status.Reason = t.Reason
+		if status.FinishTime == nil || status.FinishTime.Before(&t.FinishedAt) {
+			status.FinishTime = t.FinishedAt.DeepCopy()
+		}
+	}
+
+	j.SetIndexStatus(status)
+}
@@ -0,0 +1,193 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func indexStatusJobRun(succeeded, failed string, indices ...IndexStatus) *JobRun {
+	jr := &JobRun{Status: JobRunStatus{Indices: indices}}
+	if succeeded != "" {
+		jr.Status.SucceededIndices = &succeeded
+	}
+	if failed != "" {
+		jr.Status.FailedIndices = &failed
+	}
+	return jr
+}
+
+func TestGetIndexStatus(t *testing.T) {
+	jr := indexStatusJobRun("0-3", "4,5",
+		IndexStatus{Index: 1, Phase: corev1.PodFailed, Attempts: 2},
+		IndexStatus{Index: 5, Phase: corev1.PodRunning},
+		IndexStatus{Index: 7, Phase: corev1.PodPending},
+	)
+
+	tests := []struct {
+		idx    int64
+		want   IndexStatus
+		wantOK bool
+	}{
+		{idx: 0, want: IndexStatus{Index: 0, Phase: corev1.PodSucceeded}, wantOK: true},
+		// Entries take precedence over the index ranges.
+		{idx: 1, want: IndexStatus{Index: 1, Phase: corev1.PodFailed, Attempts: 2}, wantOK: true},
+		{idx: 4, want: IndexStatus{Index: 4, Phase: corev1.PodFailed}, wantOK: true},
+		{idx: 5, want: IndexStatus{Index: 5, Phase: corev1.PodRunning}, wantOK: true},
+		{idx: 7, want: IndexStatus{Index: 7, Phase: corev1.PodPending}, wantOK: true},
+		{idx: 6, want: IndexStatus{Index: 6}},
+	}
+	for _, test := range tests {
+		got, ok := jr.GetIndexStatus(test.idx)
+		if ok != test.wantOK || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("GetIndexStatus(%d) = %+v, %t, want %+v, %t", test.idx, got, ok, test.want, test.wantOK)
+		}
+	}
+
+	// The returned status is a copy.
+	got, _ := jr.GetIndexStatus(1)
+	got.Attempts = 10
+	if jr.Status.Indices[0].Attempts != 2 {
+		t.Error("GetIndexStatus returned the status of jr instead of a copy")
+	}
+}
+
+func TestSetIndexStatus(t *testing.T) {
+	jr := &JobRun{}
+	for _, idx := range []int64{5, 1, 9, 3} {
+		jr.SetIndexStatus(IndexStatus{Index: idx, Phase: corev1.PodRunning})
+	}
+	jr.SetIndexStatus(IndexStatus{Index: 5, Phase: corev1.PodSucceeded})
+
+	want := []IndexStatus{
+		{Index: 1, Phase: corev1.PodRunning},
+		{Index: 3, Phase: corev1.PodRunning},
+		{Index: 5, Phase: corev1.PodSucceeded},
+		{Index: 9, Phase: corev1.PodRunning},
+	}
+	if !reflect.DeepEqual(jr.Status.Indices, want) {
+		t.Errorf("indices = %+v, want %+v", jr.Status.Indices, want)
+	}
+}
+
+func TestGetIndicesInPhase(t *testing.T) {
+	jr := indexStatusJobRun("0-3", "4,5",
+		IndexStatus{Index: 1, Phase: corev1.PodFailed},
+		IndexStatus{Index: 5, Phase: corev1.PodRunning},
+		IndexStatus{Index: 7, Phase: corev1.PodPending},
+	)
+
+	tests := []struct {
+		phase corev1.PodPhase
+		want  string
+	}{
+		{phase: corev1.PodSucceeded, want: "0,2-3"},
+		{phase: corev1.PodFailed, want: "1,4"},
+		{phase: corev1.PodRunning, want: "5"},
+		{phase: corev1.PodPending, want: "7"},
+		{phase: corev1.PodUnknown, want: ""},
+	}
+	for _, test := range tests {
+		if got := jr.GetIndicesInPhase(test.phase).String(); got != test.want {
+			t.Errorf("GetIndicesInPhase(%s) = %q, want %q", test.phase, got, test.want)
+		}
+	}
+
+	// Malformed index ranges are ignored.
+	jr = indexStatusJobRun("x", "", IndexStatus{Index: 2, Phase: corev1.PodSucceeded})
+	if got := jr.GetIndicesInPhase(corev1.PodSucceeded).String(); got != "2" {
+		t.Errorf("GetIndicesInPhase(Succeeded) = %q with malformed succeededIndices, want 2", got)
+	}
+}
+
+func TestUpdateIndexStatus(t *testing.T) {
+	started := metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	finished := metav1.NewTime(started.Add(time.Minute))
+	pod := func(phase corev1.PodPhase, main corev1.ContainerState, initContainer *corev1.ContainerState) *corev1.Pod {
+		pod := &corev1.Pod{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "proxy"}, {Name: "main"}}},
+			Status: corev1.PodStatus{
+				Phase:     phase,
+				StartTime: started.DeepCopy(),
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "proxy", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+					{Name: "main", State: main},
+				},
+			},
+		}
+		if initContainer != nil {
+			pod.Spec.InitContainers = []corev1.Container{{Name: "setup"}}
+			pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{Name: "setup", State: *initContainer}}
+		}
+		return pod
+	}
+	terminated := func(exitCode int32, reason string) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: reason, FinishedAt: finished}}
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	waiting := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{}}
+	exitCode := func(c int32) *int32 { return &c }
+
+	tests := []struct {
+		name    string
+		attempt int64
+		pod     *corev1.Pod
+		want    IndexStatus
+	}{{
+		name: "running",
+		pod:  pod(corev1.PodRunning, running, nil),
+		want: IndexStatus{Index: 3, Phase: corev1.PodRunning, Attempts: 1, StartTime: &started},
+	}, {
+		// The sidecar keeps the pod running.
+		name: "main container succeeded",
+		pod:  pod(corev1.PodRunning, terminated(0, "Completed"), nil),
+		want: IndexStatus{Index: 3, Phase: corev1.PodSucceeded, Attempts: 1, LastExitCode: exitCode(0),
+			Reason: "Completed", StartTime: &started, FinishTime: &finished},
+	}, {
+		name:    "retry failed",
+		attempt: 2,
+		pod:     pod(corev1.PodRunning, terminated(137, "OOMKilled"), nil),
+		want: IndexStatus{Index: 3, Phase: corev1.PodFailed, Attempts: 3, LastExitCode: exitCode(137),
+			Reason: "OOMKilled", StartTime: &started, FinishTime: &finished},
+	}, {
+		name: "init container failed",
+		pod:  pod(corev1.PodFailed, waiting, &corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 2, Reason: "Error", FinishedAt: finished}}),
+		want: IndexStatus{Index: 3, Phase: corev1.PodFailed, Attempts: 1, LastExitCode: exitCode(2),
+			Reason: "Error", StartTime: &started, FinishTime: &finished},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jr := &JobRun{}
+			jr.Spec.JobDefinitionSpec.Template.MainContainer = "main"
+			jr.UpdateIndexStatus(3, test.attempt, test.pod)
+			if got, _ := jr.GetIndexStatus(3); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("status = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdateIndexStatusKeepsHistory(t *testing.T) {
+	first := metav1.NewTime(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(first.Add(time.Hour))
+	jr := &JobRun{}
+	jr.SetIndexStatus(IndexStatus{Index: 0, Phase: corev1.PodFailed, Attempts: 2, StartTime: &first})
+
+	// An update about an older attempt neither lowers the attempts nor moves the start time.
+	jr.UpdateIndexStatus(0, 0, &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodRunning, StartTime: &later}})
+	got, _ := jr.GetIndexStatus(0)
+	if got.Attempts != 2 || !got.StartTime.Equal(&first) || got.Phase != corev1.PodRunning {
+		t.Errorf("status = %+v, want 2 attempts started at %s", got, first)
+	}
+}
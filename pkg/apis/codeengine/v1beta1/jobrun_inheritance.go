@@ -0,0 +1,274 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// MergeJobDefinitionSpec returns the effective spec of a jobRun that refers to a jobDefinition.
+// The jobRun spec takes precedence over the jobDefinition spec:
+//   - arraySpec, retryLimit, maxExecutionTime and executionMode are inherited when not set on the jobRun
+//   - retryPolicy is inherited as a whole when not set on the jobRun
+//   - imagePullSecrets, serviceAccountName and mainContainer are inherited when empty on the jobRun
+//   - nodeSelector, tolerations, affinity and topologySpreadConstraints are inherited as a whole when not set on the jobRun
+//   - volumes are merged by name, a jobRun volume replaces the jobDefinition volume of the same name
+//   - containers and init containers of the jobDefinition are inherited, each jobRun container
+//     is merged with the jobDefinition container of the same name (see MergeContainer) and
+//     any other jobRun container is added, e.g. a sidecar.
+//     A single unnamed jobRun container is merged with the main container of the jobDefinition,
+//     or with its first init container.
+//
+// Neither of the given specs is modified.
+func MergeJobDefinitionSpec(jd, jr *JobDefinitionSpec) JobDefinitionSpec {
+	merged := *jr.DeepCopy()
+	base := jd.DeepCopy()
+
+	if merged.ArraySpec == nil {
+		merged.ArraySpec = base.ArraySpec
+	}
+	if merged.RetryLimit == nil {
+		merged.RetryLimit = base.RetryLimit
+	}
+	if merged.MaxExecutionTime == nil {
+		merged.MaxExecutionTime = base.MaxExecutionTime
+	}
+	if merged.Parallelism == nil {
+		merged.Parallelism = base.Parallelism
+	}
+	if merged.RetryPolicy == nil {
+		merged.RetryPolicy = base.RetryPolicy
+	}
+	if merged.ExecutionMode == "" {
+		merged.ExecutionMode = base.ExecutionMode
+	}
+
+	merged.Template.InitContainers = mergeContainers(base.Template.InitContainers, merged.Template.InitContainers, 0)
+	merged.Template.Containers = mergeContainers(base.Template.Containers, merged.Template.Containers, base.Template.mainContainerIndex())
+	if merged.Template.MainContainer == "" {
+		merged.Template.MainContainer = base.Template.MainContainer
+	}
+	if len(merged.Template.ImagePullSecrets) == 0 {
+		merged.Template.ImagePullSecrets = base.Template.ImagePullSecrets
+	}
+	if merged.Template.ServiceAccountName == "" {
+		merged.Template.ServiceAccountName = base.Template.ServiceAccountName
+	}
+	merged.Template.Volumes = mergeVolumes(base.Template.Volumes, merged.Template.Volumes)
+	if len(merged.Template.NodeSelector) == 0 {
+		merged.Template.NodeSelector = base.Template.NodeSelector
+	}
+	if len(merged.Template.Tolerations) == 0 {
+		merged.Template.Tolerations = base.Template.Tolerations
+	}
+	if merged.Template.Affinity == nil {
+		merged.Template.Affinity = base.Template.Affinity
+	}
+	if len(merged.Template.TopologySpreadConstraints) == 0 {
+		merged.Template.TopologySpreadConstraints = base.Template.TopologySpreadConstraints
+	}
+
+	return merged
+}
+
+// mergeContainers merges the containers of a jobRun into the ones of its jobDefinition, keeping their order.
+// Containers are matched by name. The only container of a jobRun which matches none by name
+// is merged with the container of base at index main, if that one is unnamed or the jobRun's is.
+// Containers of the jobRun which don't match are appended.
+func mergeContainers(base, overrides []corev1.Container, main int) []corev1.Container {
+	if len(overrides) == 0 {
+		return base
+	}
+
+	merged := append([]corev1.Container(nil), base...)
+	for _, c := range overrides {
+		i := -1
+		for j := range base {
+			if c.Name != "" && base[j].Name == c.Name {
+				i = j
+				break
+			}
+		}
+		if i < 0 && len(overrides) == 1 && main >= 0 && main < len(base) && (c.Name == "" || base[main].Name == "") {
+			i = main
+		}
+
+		if i >= 0 {
+			merged[i] = MergeContainer(merged[i], c)
+		} else {
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
+
+// MergeContainer returns the container inherited from base with the values of override taking precedence:
+//   - name, image, workingDir, command and args are inherited when empty
+//   - env variables are merged by name, keeping the order of base
+//   - envFrom sources are merged by configMap or secret name and prefix, new sources of override
+//     are appended to the ones of base, so they take precedence
+//   - resource limits and requests are merged by resource name
+//   - volume mounts are merged by mount path
+//   - any other field is inherited as a whole when not set on override
+func MergeContainer(base, override corev1.Container) corev1.Container {
+	merged := *base.DeepCopy()
+	override = *override.DeepCopy()
+
+	if override.Name != "" {
+		merged.Name = override.Name
+	}
+	if override.Image != "" {
+		merged.Image = override.Image
+	}
+	if override.WorkingDir != "" {
+		merged.WorkingDir = override.WorkingDir
+	}
+	if len(override.Command) > 0 {
+		merged.Command = override.Command
+	}
+	if len(override.Args) > 0 {
+		merged.Args = override.Args
+	}
+
+	merged.Env = mergeEnv(merged.Env, override.Env)
+	merged.EnvFrom = mergeEnvFrom(merged.EnvFrom, override.EnvFrom)
+	merged.Resources.Limits = mergeResourceList(merged.Resources.Limits, override.Resources.Limits)
+	merged.Resources.Requests = mergeResourceList(merged.Resources.Requests, override.Resources.Requests)
+	merged.VolumeMounts = mergeVolumeMounts(merged.VolumeMounts, override.VolumeMounts)
+
+	if len(override.Ports) > 0 {
+		merged.Ports = override.Ports
+	}
+	if len(override.VolumeDevices) > 0 {
+		merged.VolumeDevices = override.VolumeDevices
+	}
+	if override.LivenessProbe != nil {
+		merged.LivenessProbe = override.LivenessProbe
+	}
+	if override.ReadinessProbe != nil {
+		merged.ReadinessProbe = override.ReadinessProbe
+	}
+	if override.StartupProbe != nil {
+		merged.StartupProbe = override.StartupProbe
+	}
+	if override.Lifecycle != nil {
+		merged.Lifecycle = override.Lifecycle
+	}
+	if override.TerminationMessagePath != "" {
+		merged.TerminationMessagePath = override.TerminationMessagePath
+	}
+	if override.TerminationMessagePolicy != "" {
+		merged.TerminationMessagePolicy = override.TerminationMessagePolicy
+	}
+	if override.ImagePullPolicy != "" {
+		merged.ImagePullPolicy = override.ImagePullPolicy
+	}
+	if override.SecurityContext != nil {
+		merged.SecurityContext = override.SecurityContext
+	}
+
+	return merged
+}
+
+func mergeEnv(base, overrides []corev1.EnvVar) []corev1.EnvVar {
+	merged := append([]corev1.EnvVar(nil), base...)
+	for _, env := range overrides {
+		found := false
+		for i := range merged {
+			if merged[i].Name == env.Name {
+				merged[i] = env
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, env)
+		}
+	}
+	return merged
+}
+
+func mergeEnvFrom(base, overrides []corev1.EnvFromSource) []corev1.EnvFromSource {
+	merged := append([]corev1.EnvFromSource(nil), base...)
+	for _, source := range overrides {
+		found := false
+		for i := range merged {
+			if envFromKey(merged[i]) == envFromKey(source) {
+				merged[i] = source
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, source)
+		}
+	}
+	return merged
+}
+
+// envFromKey identifies an envFrom source by the configMap or secret it refers to and its prefix.
+func envFromKey(source corev1.EnvFromSource) string {
+	switch {
+	case source.ConfigMapRef != nil:
+		return "configMap/" + source.ConfigMapRef.Name + "/" + source.Prefix
+	case source.SecretRef != nil:
+		return "secret/" + source.SecretRef.Name + "/" + source.Prefix
+	}
+	return "/" + source.Prefix
+}
+
+func mergeResourceList(base, overrides corev1.ResourceList) corev1.ResourceList {
+	if len(overrides) == 0 {
+		return base
+	}
+	merged := corev1.ResourceList{}
+	for name, quantity := range base {
+		merged[name] = quantity
+	}
+	for name, quantity := range overrides {
+		merged[name] = quantity
+	}
+	return merged
+}
+
+func mergeVolumes(base, overrides []corev1.Volume) []corev1.Volume {
+	merged := append([]corev1.Volume(nil), base...)
+	for _, volume := range overrides {
+		found := false
+		for i := range merged {
+			if merged[i].Name == volume.Name {
+				merged[i] = volume
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, volume)
+		}
+	}
+	return merged
+}
+
+func mergeVolumeMounts(base, overrides []corev1.VolumeMount) []corev1.VolumeMount {
+	merged := append([]corev1.VolumeMount(nil), base...)
+	for _, mount := range overrides {
+		found := false
+		for i := range merged {
+			if merged[i].MountPath == mount.MountPath {
+				merged[i] = mount
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, mount)
+		}
+	}
+	return merged
+}
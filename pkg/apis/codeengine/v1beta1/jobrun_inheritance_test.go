@@ -0,0 +1,118 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+func TestMergeJobDefinitionSpecIsIdempotent(t *testing.T) {
+	jd := &JobDefinitionSpec{Template: JobPodTemplate{
+		Containers: []corev1.Container{{
+			Name:  "main",
+			Image: "busybox",
+			Env:   []corev1.EnvVar{{Name: "A", Value: "1"}},
+			EnvFrom: []corev1.EnvFromSource{
+				{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}}},
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret"}}, Prefix: "S_"},
+			},
+		}},
+	}}
+	jr := &JobDefinitionSpec{Template: JobPodTemplate{
+		Containers: []corev1.Container{{
+			Env: []corev1.EnvVar{{Name: "B", Value: "2"}},
+			EnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret"}}, Prefix: "S_"},
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "secret"}}, Prefix: "T_"},
+			},
+		}},
+	}}
+
+	once := MergeJobDefinitionSpec(jd, jr)
+	twice := MergeJobDefinitionSpec(jd, &once)
+	if !equality.Semantic.DeepEqual(once, twice) {
+		t.Errorf("merging an already merged spec changed it:\nonce:  %+v\ntwice: %+v", once, twice)
+	}
+	if got := len(once.Template.Containers[0].EnvFrom); got != 3 {
+		t.Errorf("merged container has %d envFrom sources, want 3: %+v", got, once.Template.Containers[0].EnvFrom)
+	}
+	if got := len(once.Template.Containers[0].Env); got != 2 {
+		t.Errorf("merged container has %d env variables, want 2", got)
+	}
+}
+
+func TestMergeJobDefinitionSpecKeepsContainers(t *testing.T) {
+	jd := &JobDefinitionSpec{Template: JobPodTemplate{
+		Containers: []corev1.Container{
+			{Name: "main", Image: "app"},
+			{Name: "proxy", Image: "proxy"},
+		},
+	}}
+	jr := &JobDefinitionSpec{Template: JobPodTemplate{
+		Containers: []corev1.Container{{Name: "main", Image: "app:v2"}},
+	}}
+
+	merged := MergeJobDefinitionSpec(jd, jr)
+	if got := len(merged.Template.Containers); got != 2 {
+		t.Fatalf("merged spec has %d containers, want the 2 of the jobDefinition", got)
+	}
+	if got := merged.Template.Containers[0].Image; got != "app:v2" {
+		t.Errorf("main container image = %q, want the jobRun's app:v2", got)
+	}
+	if got := merged.Template.Containers[1].Image; got != "proxy" {
+		t.Errorf("proxy container image = %q, want the jobDefinition's proxy", got)
+	}
+}
+
+func TestMergeJobDefinitionSpecVolumes(t *testing.T) {
+	volume := func(name, secret string) corev1.Volume {
+		return corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secret}}}
+	}
+	jd := &JobDefinitionSpec{Template: JobPodTemplate{
+		Containers: []corev1.Container{{
+			Name:  "main",
+			Image: "main",
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "config", MountPath: "/etc/config"},
+				{Name: "data", MountPath: "/data"},
+			},
+		}},
+		Volumes: []corev1.Volume{volume("config", "config-v1"), volume("data", "data")},
+	}}
+	jr := &JobDefinitionSpec{Template: JobPodTemplate{
+		Containers: []corev1.Container{{
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "data", MountPath: "/data", ReadOnly: true},
+				{Name: "cache", MountPath: "/cache"},
+			},
+		}},
+		Volumes: []corev1.Volume{volume("cache", "cache"), volume("config", "config-v2")},
+	}}
+
+	merged := MergeJobDefinitionSpec(jd, jr)
+
+	wantVolumes := []corev1.Volume{volume("config", "config-v2"), volume("data", "data"), volume("cache", "cache")}
+	if !equality.Semantic.DeepEqual(merged.Template.Volumes, wantVolumes) {
+		t.Errorf("volumes = %+v, want %+v", merged.Template.Volumes, wantVolumes)
+	}
+	wantMounts := []corev1.VolumeMount{
+		{Name: "config", MountPath: "/etc/config"},
+		{Name: "data", MountPath: "/data", ReadOnly: true},
+		{Name: "cache", MountPath: "/cache"},
+	}
+	if got := merged.Template.Containers[0].VolumeMounts; !equality.Semantic.DeepEqual(got, wantMounts) {
+		t.Errorf("volume mounts = %+v, want %+v", got, wantMounts)
+	}
+	if len(jd.Template.Volumes) != 2 || jd.Template.Volumes[0].Secret.SecretName != "config-v1" {
+		t.Error("MergeJobDefinitionSpec modified the jobDefinition")
+	}
+}
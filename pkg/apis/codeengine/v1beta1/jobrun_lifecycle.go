@@ -9,9 +9,25 @@
 package v1beta1
 
 import (
+	"fmt"
+	"regexp"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// rerunInfix separates the name of the rerun jobRun from the suffix generated by the API server.
+	rerunInfix = "-rerun-"
+	// generatedSuffixLength is the number of random characters the API server appends to GenerateName.
+	generatedSuffixLength = 5
+	// maxRerunBaseLength keeps the names of reruns valid label values, as they are copied into the LabelJobRun pod label.
+	maxRerunBaseLength = validation.LabelValueMaxLength - len(rerunInfix) - generatedSuffixLength
 )
 
+var rerunSuffix = regexp.MustCompile(rerunInfix + "[a-z0-9]{5}$")
+
 // AddLabel Add one label(key: value) to JobRun.
 func (jr *JobRun) AddLabel(key, value string, overwrite bool) {
 	if jr.Labels == nil {
@@ -28,3 +44,57 @@ func (jr *JobRun) AddLabel(key, value string, overwrite bool) {
 func (jr *JobRun) SetOwner(jd *JobDefinition) {
 	jr.OwnerReferences = append(jr.OwnerReferences, *metav1.NewControllerRef(jd, SchemeGroupVersion.WithKind("JobDefinition")))
 }
+
+// NewRerunOfFailedIndices returns a new JobRun that repeats exactly the failed indices of jr.
+// The rerun keeps the jobDefinitionRef, spec, labels, annotations (except for the
+// job controller bookkeeping) and owners of jr,
+// is named after jr by GenerateName, without repeating the suffix of jr if it's a rerun itself,
+// and refers back to jr by the LabelRerunOf label
+// and the AnnotationRerunOfUID annotation.
+// An error is returned if jr has no failed indices.
+func (jr *JobRun) NewRerunOfFailedIndices() (*JobRun, error) {
+	failed, err := jr.GetFailedIndexSet()
+	if err != nil {
+		return nil, err
+	}
+	if failed.IsEmpty() {
+		return nil, fmt.Errorf("jobRun %q has no failed indices", jr.Name)
+	}
+
+	rerun := &JobRun{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName:    rerunBaseName(jr.Name) + rerunInfix,
+			Namespace:       jr.Namespace,
+			Labels:          map[string]string{},
+			Annotations:     map[string]string{},
+			OwnerReferences: append([]metav1.OwnerReference(nil), jr.OwnerReferences...),
+		},
+		Spec: *jr.Spec.DeepCopy(),
+	}
+	for k, v := range jr.Labels {
+		rerun.Labels[k] = v
+	}
+	for k, v := range jr.Annotations {
+		rerun.Annotations[k] = v
+	}
+	// Bookkeeping of the job controller doesn't apply to the rerun.
+	delete(rerun.Annotations, AnnotationRetryTimes)
+	delete(rerun.Annotations, AnnotationPodExpectations)
+	rerun.Labels[LabelRerunOf] = jr.Name
+	rerun.Annotations[AnnotationRerunOfUID] = string(jr.UID)
+
+	arraySpec := failed.String()
+	rerun.Spec.JobDefinitionSpec.ArraySpec = &arraySpec
+
+	return rerun, nil
+}
+
+// rerunBaseName returns the name the reruns of the named jobRun are named after:
+// the name of the original jobRun, shortened to keep the names of the reruns valid label values.
+func rerunBaseName(name string) string {
+	name = rerunSuffix.ReplaceAllString(name, "")
+	if len(name) > maxRerunBaseLength {
+		name = strings.TrimRight(name[:maxRerunBaseLength], "-.")
+	}
+	return name
+}
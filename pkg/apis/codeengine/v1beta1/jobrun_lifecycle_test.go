@@ -0,0 +1,56 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestRerunBaseName(t *testing.T) {
+	long := strings.Repeat("a", 60)
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "job", want: "job"},
+		{name: "job-rerun-x7k2p", want: "job"},
+		{name: "job-rerun-abc", want: "job-rerun-abc"},
+		{name: long, want: long[:maxRerunBaseLength]},
+		{name: long[:maxRerunBaseLength-1] + "-b", want: long[:maxRerunBaseLength-1]},
+	}
+	for _, tt := range tests {
+		if got := rerunBaseName(tt.name); got != tt.want {
+			t.Errorf("rerunBaseName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRerunOfRerunKeepsValidName(t *testing.T) {
+	failed := "1"
+	jr := &JobRun{}
+	jr.Name = strings.Repeat("a", 50)
+	jr.Status.FailedIndices = &failed
+	for i := 0; i < 5; i++ {
+		rerun, err := jr.NewRerunOfFailedIndices()
+		if err != nil {
+			t.Fatalf("NewRerunOfFailedIndices: %v", err)
+		}
+		// The API server appends 5 random characters to GenerateName.
+		name := rerun.GenerateName + "x7k2p"
+		if msgs := validation.IsValidLabelValue(name); len(msgs) > 0 {
+			t.Fatalf("rerun %d is named %q, which is no valid label value: %v", i, name, msgs)
+		}
+		rerun.Name, rerun.GenerateName = name, ""
+		rerun.Status.FailedIndices = &failed
+		jr = rerun
+	}
+}
@@ -0,0 +1,89 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"knative.dev/pkg/apis"
+)
+
+// JobRunPhase is the phase of a jobRun, derived from its conditions.
+type JobRunPhase string
+
+const (
+	// JobRunPhaseUnknown means the jobRun has no status yet or its status is stale.
+	JobRunPhaseUnknown JobRunPhase = "Unknown"
+	// JobRunPhasePending means the jobRun has been submitted into the system.
+	JobRunPhasePending JobRunPhase = "Pending"
+	// JobRunPhaseRunning means at least one pod of the jobRun is running.
+	JobRunPhaseRunning JobRunPhase = "Running"
+	// JobRunPhaseComplete means the jobRun has completed its execution.
+	JobRunPhaseComplete JobRunPhase = "Complete"
+	// JobRunPhaseFailed means the jobRun has failed its execution.
+	JobRunPhaseFailed JobRunPhase = "Failed"
+)
+
+// jobRunPhaseTransitions lists the legal phases to follow each phase.
+// A jobRun may stay in its phase, the Unknown phase may be followed by any phase
+// and the finished phases Complete and Failed are terminal.
+var jobRunPhaseTransitions = map[JobRunPhase][]JobRunPhase{
+	JobRunPhaseUnknown:  {JobRunPhaseUnknown, JobRunPhasePending, JobRunPhaseRunning, JobRunPhaseComplete, JobRunPhaseFailed},
+	JobRunPhasePending:  {JobRunPhasePending, JobRunPhaseRunning, JobRunPhaseComplete, JobRunPhaseFailed},
+	JobRunPhaseRunning:  {JobRunPhaseRunning, JobRunPhaseComplete, JobRunPhaseFailed},
+	JobRunPhaseComplete: {JobRunPhaseComplete},
+	JobRunPhaseFailed:   {JobRunPhaseFailed},
+}
+
+// IsFinished returns true for the terminal phases Complete and Failed.
+func (p JobRunPhase) IsFinished() bool {
+	return p == JobRunPhaseComplete || p == JobRunPhaseFailed
+}
+
+// CanTransitionTo returns true if a jobRun may move from phase p to next.
+func (p JobRunPhase) CanTransitionTo(next JobRunPhase) bool {
+	for _, legal := range jobRunPhaseTransitions[p] {
+		if legal == next {
+			return true
+		}
+	}
+	return false
+}
+
+// Phase returns the phase of the jobRun derived from its conditions.
+// The first true condition of Failed, Complete, Running and Pending determines the phase,
+// so the order of the conditions doesn't matter.
+// The phase is Unknown if no such condition is true or the status is stale.
+func (j *JobRun) Phase() JobRunPhase {
+	if j.IsStale() {
+		return JobRunPhaseUnknown
+	}
+	for _, t := range []JobRunConditionType{JobFailed, JobComplete, JobRunning, JobPending} {
+		if cond := j.Status.GetCondition(t); cond != nil && cond.Status == corev1.ConditionTrue {
+			return JobRunPhase(t)
+		}
+	}
+	return JobRunPhaseUnknown
+}
+
+// ValidatePhaseTransition validates that the status update from old to new
+// follows the legal transitions of the jobRun phases, e.g. Complete can't be followed by Running.
+// A stale status, e.g. of a jobRun whose spec was just updated, doesn't tell the phase,
+// so updates from or to a stale status aren't checked.
+func ValidatePhaseTransition(old, new *JobRun) *apis.FieldError {
+	if old.IsStale() || new.IsStale() {
+		return nil
+	}
+	from, to := old.Phase(), new.Phase()
+	if !from.CanTransitionTo(to) {
+		return apis.ErrGeneric(fmt.Sprintf("illegal phase transition from %s to %s", from, to), "status.conditions")
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/utils/clock"
+)
+
+var allJobRunPhases = []JobRunPhase{JobRunPhaseUnknown, JobRunPhasePending, JobRunPhaseRunning, JobRunPhaseComplete, JobRunPhaseFailed}
+
+func TestCanTransitionTo(t *testing.T) {
+	// legal[from] lists the phases which may follow from.
+	legal := map[JobRunPhase]string{
+		JobRunPhaseUnknown:  "Unknown Pending Running Complete Failed",
+		JobRunPhasePending:  "Pending Running Complete Failed",
+		JobRunPhaseRunning:  "Running Complete Failed",
+		JobRunPhaseComplete: "Complete",
+		JobRunPhaseFailed:   "Failed",
+	}
+	for _, from := range allJobRunPhases {
+		for _, to := range allJobRunPhases {
+			want := false
+			for _, p := range strings.Fields(legal[from]) {
+				want = want || JobRunPhase(p) == to
+			}
+			if got := from.CanTransitionTo(to); got != want {
+				t.Errorf("%s.CanTransitionTo(%s) = %t, want %t", from, to, got, want)
+			}
+		}
+	}
+}
+
+// phaseJobRun returns a jobRun of the given generation in the given phase,
+// whose status was observed at the given generation.
+func phaseJobRun(phase JobRunPhase, generation, observedGeneration int64) *JobRun {
+	jr := &JobRun{}
+	jr.Generation = generation
+	jr.Status.ObservedGeneration = observedGeneration
+	m := jr.Status.ManageConditions(clock.RealClock{})
+	switch phase {
+	case JobRunPhasePending:
+		m.MarkPending()
+	case JobRunPhaseRunning:
+		m.MarkRunning()
+	case JobRunPhaseComplete:
+		m.MarkComplete()
+	case JobRunPhaseFailed:
+		m.MarkFailed("IndexFailed", "index 0 failed")
+	}
+	return jr
+}
+
+func TestPhase(t *testing.T) {
+	for _, phase := range allJobRunPhases {
+		if got := phaseJobRun(phase, 1, 1).Phase(); got != phase {
+			t.Errorf("Phase() = %s, want %s", got, phase)
+		}
+		if got := phaseJobRun(phase, 2, 1).Phase(); got != JobRunPhaseUnknown {
+			t.Errorf("Phase() of a stale %s jobRun = %s, want Unknown", phase, got)
+		}
+	}
+}
+
+func TestValidatePhaseTransition(t *testing.T) {
+	for _, from := range allJobRunPhases {
+		for _, to := range allJobRunPhases {
+			err := ValidatePhaseTransition(phaseJobRun(from, 1, 1), phaseJobRun(to, 1, 1))
+			if legal := from.CanTransitionTo(to); legal != (err == nil) {
+				t.Errorf("ValidatePhaseTransition(%s, %s) = %v, want legal: %t", from, to, err, legal)
+			}
+
+			// A spec update makes the status stale until the server observes it.
+			if err := ValidatePhaseTransition(phaseJobRun(from, 1, 1), phaseJobRun(to, 2, 1)); err != nil {
+				t.Errorf("ValidatePhaseTransition(%s, stale %s) = %v, want nil", from, to, err)
+			}
+			if err := ValidatePhaseTransition(phaseJobRun(from, 2, 1), phaseJobRun(to, 2, 2)); err != nil {
+				t.Errorf("ValidatePhaseTransition(stale %s, %s) = %v, want nil", from, to, err)
+			}
+		}
+	}
+
+	// Servers which don't report observedGeneration are checked.
+	if err := ValidatePhaseTransition(phaseJobRun(JobRunPhaseComplete, 2, 0), phaseJobRun(JobRunPhaseRunning, 2, 0)); err == nil {
+		t.Error("ValidatePhaseTransition(Complete, Running) without observedGeneration succeeded, want an error")
+	}
+}
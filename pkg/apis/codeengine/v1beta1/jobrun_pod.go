@@ -0,0 +1,112 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PodOptions holds the project settings which are exposed to jobRun pods,
+// but are not part of the JobRun resource.
+// +k8s:openapi-gen=false
+type PodOptions struct {
+	// Domain is exposed to the containers as CE_DOMAIN, if set.
+	Domain string
+	// Subdomain is exposed to the containers as CE_SUBDOMAIN, if set.
+	Subdomain string
+}
+
+// PodName returns the name of the pod created for the given index and attempt.
+func (jr *JobRun) PodName(idx int64, attempt int64) string {
+	return fmt.Sprintf("%s-%d-%d", jr.Name, idx, attempt)
+}
+
+// PodForIndex renders the pod that runs the given index of the jobRun, the way the job controller creates it.
+// The jobRun is expected to be defaulted, including defaulting from its jobDefinition.
+// Every container and init container of the template gets the environment variables JOB_INDEX, CE_JOB and CE_JOBRUN,
+// plus CE_DOMAIN and CE_SUBDOMAIN from opts and CE_EXECUTION_MODE in daemon mode.
+// The pods are spread across topology domains as described by IndexSpreadConstraints.
+// An error is returned if idx is not part of the arraySpec or the template has no container.
+func (jr *JobRun) PodForIndex(idx int64, opts PodOptions) (*corev1.Pod, error) {
+	jds := &jr.Spec.JobDefinitionSpec
+
+	indices, err := jds.GetArrayIndices()
+	if err != nil {
+		return nil, fmt.Errorf("invalid arraySpec: %w", err)
+	}
+	if jds.ArraySpec != nil && !indices.Contains(idx) {
+		return nil, fmt.Errorf("index %d is not part of arraySpec %q", idx, *jds.ArraySpec)
+	}
+	if len(jds.Template.Containers) == 0 {
+		return nil, fmt.Errorf("jobRun %q has no containers", jr.Name)
+	}
+
+	labels := map[string]string{
+		LabelJobIndex: strconv.FormatInt(idx, 10),
+		LabelPodType:  JobRunType,
+		LabelJobRun:   jr.Name,
+	}
+	for _, key := range []string{LabelJobDefName, LabelJobDefUUID} {
+		if value, ok := jr.Labels[key]; ok {
+			labels[key] = value
+		}
+	}
+
+	env := []corev1.EnvVar{
+		{Name: JobIndex, Value: strconv.FormatInt(idx, 10)},
+		{Name: CEJob, Value: jr.Spec.JobDefinitionRef},
+		{Name: CEJobRun, Value: jr.Name},
+	}
+	if opts.Domain != "" {
+		env = append(env, corev1.EnvVar{Name: CEDomain, Value: opts.Domain})
+	}
+	if opts.Subdomain != "" {
+		env = append(env, corev1.EnvVar{Name: CESubDomain, Value: opts.Subdomain})
+	}
+	if jds.IsDaemon() {
+		env = append(env, corev1.EnvVar{Name: CEExecutionMode, Value: CEExecutionModeValue})
+	}
+
+	template := jds.Template.DeepCopy()
+	for i := range template.InitContainers {
+		template.InitContainers[i].Env = mergeEnv(template.InitContainers[i].Env, env)
+	}
+	for i := range template.Containers {
+		template.Containers[i].Env = mergeEnv(template.Containers[i].Env, env)
+	}
+
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Pod",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            jr.PodName(idx, 0),
+			Namespace:       jr.Namespace,
+			Labels:          labels,
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(jr, SchemeGroupVersion.WithKind("JobRun"))},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers:            template.InitContainers,
+			Containers:                template.Containers,
+			ImagePullSecrets:          template.ImagePullSecrets,
+			ServiceAccountName:        template.ServiceAccountName,
+			Volumes:                   template.Volumes,
+			NodeSelector:              template.NodeSelector,
+			Tolerations:               template.Tolerations,
+			Affinity:                  template.Affinity,
+			TopologySpreadConstraints: jr.IndexSpreadConstraints(),
+			RestartPolicy:             corev1.RestartPolicyNever,
+		},
+	}, nil
+}
@@ -0,0 +1,168 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func podJobRun() *JobRun {
+	return &JobRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "run",
+			Namespace: "ns",
+			UID:       "uid",
+			Labels:    map[string]string{LabelJobDefName: "jd", "team": "a"},
+		},
+		Spec: JobRunSpec{
+			JobDefinitionRef: "jd",
+			JobDefinitionSpec: JobDefinitionSpec{
+				ArraySpec: pointer.String("0-3"),
+				Template: JobPodTemplate{
+					Containers: []corev1.Container{{
+						Name:  "main",
+						Image: "icr.io/codeengine/helloworld",
+						Env:   []corev1.EnvVar{{Name: "TARGET", Value: "world"}, {Name: JobIndex, Value: "overridden"}},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func envMap(env []corev1.EnvVar) map[string]string {
+	m := map[string]string{}
+	for _, e := range env {
+		m[e.Name] = e.Value
+	}
+	return m
+}
+
+func TestPodForIndex(t *testing.T) {
+	jr := podJobRun()
+	pod, err := jr.PodForIndex(2, PodOptions{Domain: "example.com"})
+	if err != nil {
+		t.Fatalf("PodForIndex: %v", err)
+	}
+
+	if pod.Name != "run-2-0" || pod.Namespace != "ns" {
+		t.Errorf("pod is %s/%s, want ns/run-2-0", pod.Namespace, pod.Name)
+	}
+	wantLabels := map[string]string{
+		LabelJobIndex:   "2",
+		LabelPodType:    JobRunType,
+		LabelJobRun:     "run",
+		LabelJobDefName: "jd",
+	}
+	if !reflect.DeepEqual(pod.Labels, wantLabels) {
+		t.Errorf("labels = %v, want %v", pod.Labels, wantLabels)
+	}
+	if refs := pod.OwnerReferences; len(refs) != 1 || refs[0].UID != "uid" || refs[0].Kind != "JobRun" || !*refs[0].Controller {
+		t.Errorf("ownerReferences = %+v, want the jobRun as controller", refs)
+	}
+	if pod.Spec.RestartPolicy != corev1.RestartPolicyNever {
+		t.Errorf("restartPolicy = %s, want Never", pod.Spec.RestartPolicy)
+	}
+
+	wantEnv := map[string]string{
+		"TARGET": "world",
+		JobIndex: "2",
+		CEJob:    "jd",
+		CEJobRun: "run",
+		CEDomain: "example.com",
+	}
+	if got := envMap(pod.Spec.Containers[0].Env); !reflect.DeepEqual(got, wantEnv) {
+		t.Errorf("env = %v, want %v", got, wantEnv)
+	}
+	if len(jr.Spec.JobDefinitionSpec.Template.Containers[0].Env) != 2 {
+		t.Error("PodForIndex modified the template of the jobRun")
+	}
+}
+
+func TestPodForIndexDaemon(t *testing.T) {
+	jr := podJobRun()
+	jr.Spec.JobDefinitionSpec.ExecutionMode = ExecutionModeDaemon
+	pod, err := jr.PodForIndex(0, PodOptions{})
+	if err != nil {
+		t.Fatalf("PodForIndex: %v", err)
+	}
+	env := envMap(pod.Spec.Containers[0].Env)
+	if env[CEExecutionMode] != CEExecutionModeValue {
+		t.Errorf("%s = %q, want %q", CEExecutionMode, env[CEExecutionMode], CEExecutionModeValue)
+	}
+	if _, ok := env[CEDomain]; ok {
+		t.Errorf("%s is set without a domain", CEDomain)
+	}
+}
+
+func TestPodForIndexInitContainersAndSidecars(t *testing.T) {
+	jr := podJobRun()
+	jr.Spec.JobDefinitionSpec.ExecutionMode = ExecutionModeDaemon
+	template := &jr.Spec.JobDefinitionSpec.Template
+	template.InitContainers = []corev1.Container{
+		{Name: "setup", Image: "setup", Env: []corev1.EnvVar{{Name: "STEP", Value: "1"}}},
+		{Name: "download", Image: "download"},
+	}
+	template.Containers = append(template.Containers, corev1.Container{Name: "proxy", Image: "proxy"})
+
+	pod, err := jr.PodForIndex(1, PodOptions{Subdomain: "run"})
+	if err != nil {
+		t.Fatalf("PodForIndex: %v", err)
+	}
+
+	var names []string
+	for _, c := range pod.Spec.InitContainers {
+		names = append(names, c.Name)
+	}
+	for _, c := range pod.Spec.Containers {
+		names = append(names, c.Name)
+	}
+	if want := []string{"setup", "download", "main", "proxy"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("containers = %v, want %v in their order", names, want)
+	}
+
+	injected := map[string]string{
+		JobIndex:        "1",
+		CEJob:           "jd",
+		CEJobRun:        "run",
+		CESubDomain:     "run",
+		CEExecutionMode: CEExecutionModeValue,
+	}
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		env := envMap(c.Env)
+		for name, value := range injected {
+			if env[name] != value {
+				t.Errorf("container %s has %s=%q, want %q", c.Name, name, env[name], value)
+			}
+		}
+	}
+	if env := pod.Spec.InitContainers[0].Env; env[0].Name != "STEP" || env[0].Value != "1" {
+		t.Errorf("the env of the init container is %v, want its own variables first", env)
+	}
+	if len(template.InitContainers[1].Env) != 0 {
+		t.Error("PodForIndex modified the init containers of the jobRun")
+	}
+}
+
+func TestPodForIndexErrors(t *testing.T) {
+	jr := podJobRun()
+	if _, err := jr.PodForIndex(4, PodOptions{}); err == nil {
+		t.Error("PodForIndex succeeded for an index outside of the arraySpec")
+	}
+
+	jr.Spec.JobDefinitionSpec.Template.Containers = nil
+	if _, err := jr.PodForIndex(0, PodOptions{}); err == nil {
+		t.Error("PodForIndex succeeded without containers")
+	}
+}
@@ -28,9 +28,6 @@ package v1beta1
 import (
 	"fmt"
 	"reflect"
-	"sort"
-	"strconv"
-	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -50,11 +47,17 @@ var (
 	LabelJobDefName = fmt.Sprintf("%s/job-definition-name", codeengine.GroupName)
 	// LabelJobDefUUID is the label key for job definition uuid
 	LabelJobDefUUID = fmt.Sprintf("%s/job-definition-uuid", codeengine.GroupName)
+	// LabelRerunOf is the label key for the name of the job run that a rerun repeats
+	LabelRerunOf = fmt.Sprintf("%s/rerun-of", codeengine.GroupName)
 
-	// AnnotationRetryTimes is the annotation key for retry times
+	// AnnotationRetryTimes is the annotation key for retry times.
+	// RetryTimes decodes it client-side, the job controller doesn't guarantee its format.
 	AnnotationRetryTimes = fmt.Sprintf("%s/retry-times", codeengine.GroupName)
-	// AnnotationPodExpectations is the annotation key for counting pod expectations
+	// AnnotationPodExpectations is the annotation key for counting pod expectations.
+	// PodExpectations decodes it client-side, the job controller doesn't guarantee its format.
 	AnnotationPodExpectations = fmt.Sprintf("%s/pod-expectations", codeengine.GroupName)
+	// AnnotationRerunOfUID is the annotation key for the uid of the job run that a rerun repeats
+	AnnotationRerunOfUID = fmt.Sprintf("%s/rerun-of-uid", codeengine.GroupName)
 )
 
 const (
@@ -71,7 +74,8 @@ const (
 	CEJob = "CE_JOB"
 	// CodeEngine Job Run
 	CEJobRun = "CE_JOBRUN"
-	// Mode for "daemon" Job Run
+	// Mode for "daemon" Job Run, superseded by JobDefinitionSpec.ExecutionMode
+	// and translated from and to it for older clients and servers
 	CEExecutionMode      = "CE_EXECUTION_MODE"
 	CEExecutionModeValue = "DAEMON"
 	// Note:
@@ -105,11 +109,21 @@ type JobRunSpec struct {
 
 	// The spec for a jobDefinition resource
 	JobDefinitionSpec JobDefinitionSpec `json:"jobDefinitionSpec,omitempty"`
+
+	// Priority of the jobRun relative to the other jobRuns of the namespace, higher values first.
+	// It orders the submission of jobRuns held back by a client-side queue.
+	// Default value is 0 if not set.
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
 }
 
 // JobRunStatus is the current status of a jobRun resource
 type JobRunStatus struct {
 
+	// ObservedGeneration is the 'Generation' of the jobRun that was last processed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Represents time when the job was acknowledged by the job controller.
 	// It is not guaranteed to be set in happens-before order across separate operations.
 	// It is represented in RFC3339 form and is in UTC.
@@ -135,6 +149,8 @@ type JobRunStatus struct {
 
 	// The latest available observations of an object's current state.
 	// +optional
+	// +listType=map
+	// +listMapKey=type
 	Conditions []JobRunCondition `json:"conditions,omitempty"`
 
 	// The number of pods which reached phase Unknown.
@@ -158,8 +174,51 @@ type JobRunStatus struct {
 	Failed int64 `json:"failed,omitempty"`
 
 	// The number of pods which are requested but not created.
+	// Indices held back by the parallelism of the jobRun are not included.
 	// +optional
 	Requested int64 `json:"requested,omitempty"`
+
+	// The number of pods which are not created yet, because the jobRun already runs
+	// as many indices at the same time as its parallelism allows.
+	// +optional
+	Throttled int64 `json:"throttled,omitempty"`
+
+	// Status of individual indices, sorted by index.
+	// Servers may leave it empty and only report failedIndices and succeededIndices.
+	// +optional
+	// +listType=map
+	// +listMapKey=index
+	Indices []IndexStatus `json:"indices,omitempty"`
+}
+
+// IndexStatus is the status of a single index of a jobRun
+type IndexStatus struct {
+	// The index of the jobRun.
+	Index int64 `json:"index"`
+
+	// Phase of the latest pod of the index.
+	// +optional
+	Phase corev1.PodPhase `json:"phase,omitempty"`
+
+	// Number of pods created for the index, including retries.
+	// +optional
+	Attempts int64 `json:"attempts,omitempty"`
+
+	// Exit code of the latest terminated container of the index.
+	// +optional
+	LastExitCode *int32 `json:"lastExitCode,omitempty"`
+
+	// (brief) reason for the latest termination of the index.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Time when the first pod of the index started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Time when the latest pod of the index finished.
+	// +optional
+	FinishTime *metav1.Time `json:"finishTime,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -192,6 +251,7 @@ func (s *JobRunStatus) GetLatestCondition() *JobRunCondition {
 
 // AddCondition sets or updates new condition on conditions,
 // put down condition when update.
+// Use ManageConditions to maintain the top-level Succeeded condition as well.
 func (s *JobRunStatus) AddCondition(new JobRunCondition) {
 	var newConditions []JobRunCondition
 	for _, c := range s.Conditions {
@@ -230,6 +290,7 @@ func (j *JobRun) IsJobRunFinished() bool {
 }
 
 // UpdateStatusCounts updates job pods status counts.
+// The pods missing from podStatus are counted as requested, or as throttled beyond the parallelism of the jobRun.
 func (j *JobRun) UpdateStatusCounts(total int64, podStatus []corev1.PodPhase) {
 	var unknown, pending, running, succeeded, failed int64
 	for _, ps := range podStatus {
@@ -252,7 +313,20 @@ func (j *JobRun) UpdateStatusCounts(total int64, podStatus []corev1.PodPhase) {
 	j.Status.Running = running
 	j.Status.Succeeded = succeeded
 	j.Status.Failed = failed
-	j.Status.Requested = total - int64(len(podStatus))
+
+	missing := total - int64(len(podStatus))
+	j.Status.Requested = missing
+	j.Status.Throttled = 0
+	if parallelism := j.Spec.JobDefinitionSpec.Parallelism; parallelism != nil {
+		free := *parallelism - (unknown + pending + running)
+		if free < 0 {
+			free = 0
+		}
+		if missing > free {
+			j.Status.Requested = free
+			j.Status.Throttled = missing - free
+		}
+	}
 }
 
 // UpdateFailedIndices updates jr.Status.FailedIndices.
@@ -261,114 +335,76 @@ func (j *JobRun) UpdateFailedIndices(podSnapshots map[int64]corev1.PodPhase) {
 	if len(podSnapshots) == 0 || j.IsRunningInDaemonMode() {
 		return
 	}
-	// indexRange is a string containing consecutive indices, such as: 2-5.
-	indexRanges := generateIndexRanges(podSnapshots, false)
-
-	failedIndices := strings.Join(indexRanges, ",")
+	failedIndices := indexSetFromSnapshots(podSnapshots, false).String()
 
 	if len(failedIndices) != 0 && (j.Status.FailedIndices == nil || failedIndices != *j.Status.FailedIndices) {
 		j.Status.FailedIndices = &failedIndices
 	}
 }
 
-// GetSucceededIndices get succeeded indices map from jr.Status.SucceededIndices.
-func (j *JobRun) GetSucceededIndices() map[int64]corev1.PodPhase {
-	indexStatus := map[int64]corev1.PodPhase{}
-	if j.Status.SucceededIndices == nil || *j.Status.SucceededIndices == "" {
-		return indexStatus
+// GetSucceededIndexSet returns every index listed in jr.Status.SucceededIndices.
+// An error is returned if the status can't be parsed.
+func (j *JobRun) GetSucceededIndexSet() (IndexSet, error) {
+	set, err := j.Status.GetSucceededIndexSet()
+	if err != nil {
+		return IndexSet{}, fmt.Errorf("invalid succeeded indices: %w", err)
 	}
-
-	succeededIndices := strings.Split(*j.Status.SucceededIndices, ",")
-
-	return j.GetIndicesWithStatus(corev1.PodSucceeded, succeededIndices)
+	return set, nil
 }
 
-// GetFailedIndices get failed indices map from jr.Status.FailedIndices.
-func (j *JobRun) GetFailedIndices() map[int64]corev1.PodPhase {
-	indexStatus := map[int64]corev1.PodPhase{}
-	if j.Status.FailedIndices == nil || *j.Status.FailedIndices == "" {
-		return indexStatus
-	}
-
-	failedIndices := strings.Split(*j.Status.FailedIndices, ",")
-
-	return j.GetIndicesWithStatus(corev1.PodFailed, failedIndices)
-}
-
-func (j *JobRun) GetIndicesWithStatus(status corev1.PodPhase, indices []string) map[int64]corev1.PodPhase {
-	indexStatus := map[int64]corev1.PodPhase{}
-	for _, ci := range indices {
-		indices := strings.Split(ci, "-")
-		if len(indices) == 1 {
-			idx, err := strconv.ParseInt(indices[0], 10, 64)
-			if err != nil {
-				continue
-			}
-			indexStatus[idx] = status
-		} else if len(indices) == 2 {
-			startIdx, err := strconv.ParseInt(indices[0], 10, 64)
-			if err != nil {
-				continue
-			}
-			indexStatus[startIdx] = status
-
-			endIdx, err := strconv.ParseInt(indices[1], 10, 64)
-			if err != nil {
-				continue
-			}
-			indexStatus[endIdx] = status
-		}
+// GetFailedIndexSet returns every index listed in jr.Status.FailedIndices.
+// An error is returned if the status can't be parsed.
+func (j *JobRun) GetFailedIndexSet() (IndexSet, error) {
+	set, err := j.Status.GetFailedIndexSet()
+	if err != nil {
+		return IndexSet{}, fmt.Errorf("invalid failed indices: %w", err)
 	}
-
-	return indexStatus
+	return set, nil
 }
 
 // UpdateSucceededIndices updates jr.Status.SucceededIndices.
 func (j *JobRun) UpdateSucceededIndices(podSnapshots map[int64]corev1.PodPhase) {
-	indexRanges := generateIndexRanges(podSnapshots, true)
-
-	succeededIndices := strings.Join(indexRanges, ",")
+	succeededIndices := indexSetFromSnapshots(podSnapshots, true).String()
 
 	if len(succeededIndices) != 0 && (j.Status.SucceededIndices == nil || succeededIndices != *j.Status.SucceededIndices) {
 		j.Status.SucceededIndices = &succeededIndices
 	}
 }
 
-func generateIndexRanges(podSnapshots map[int64]corev1.PodPhase, expectSucceeded bool) (indexRanges []string) {
-	var indexKeys []int64
-	for idx := range podSnapshots {
-		indexKeys = append(indexKeys, idx)
+// GetArrayIndices returns the set of indices specified by the arraySpec.
+// An unset arraySpec yields an empty set.
+func (jds *JobDefinitionSpec) GetArrayIndices() (IndexSet, error) {
+	if jds.ArraySpec == nil {
+		return IndexSet{}, nil
 	}
+	return ParseIndexSet(*jds.ArraySpec)
+}
 
-	sort.Slice(indexKeys, func(l, u int) bool { return indexKeys[l] < indexKeys[u] })
-
-	// Initialize first indexRange.
-	for _, idx := range indexKeys {
-		key := strconv.FormatInt(idx, 10)
-		// Pick up index by XOR operator between non-succeeded phase and expected succeeded phase.
-		if ps := podSnapshots[idx]; (ps != corev1.PodSucceeded) != expectSucceeded {
+// GetFailedIndexSet returns the set of indices from jr.Status.FailedIndices.
+func (s *JobRunStatus) GetFailedIndexSet() (IndexSet, error) {
+	if s.FailedIndices == nil {
+		return IndexSet{}, nil
+	}
+	return ParseIndexSet(*s.FailedIndices)
+}
 
-			// Initialize first indexRange.
-			if len(indexRanges) == 0 {
-				indexRanges = append(indexRanges, key)
-				continue
-			}
+// GetSucceededIndexSet returns the set of indices from jr.Status.SucceededIndices.
+func (s *JobRunStatus) GetSucceededIndexSet() (IndexSet, error) {
+	if s.SucceededIndices == nil {
+		return IndexSet{}, nil
+	}
+	return ParseIndexSet(*s.SucceededIndices)
+}
 
-			// Merge consecutive indices.
-			lastIndexRange := indexRanges[len(indexRanges)-1]
-			// Slice `indices` at least have one index number.
-			indices := strings.Split(lastIndexRange, "-")
-			lastIndex, err := strconv.ParseInt(indices[len(indices)-1], 10, 64)
-			if err == nil && lastIndex+1 == idx {
-				replaceRange := fmt.Sprintf("%s-%d", indices[0], idx)
-				indexRanges = append(indexRanges[:len(indexRanges)-1], replaceRange)
-			} else {
-				indexRanges = append(indexRanges, key)
-			}
+// indexSetFromSnapshots picks up indices by XOR operator between non-succeeded phase and expected succeeded phase.
+func indexSetFromSnapshots(podSnapshots map[int64]corev1.PodPhase, expectSucceeded bool) IndexSet {
+	var indices []int64
+	for idx, ps := range podSnapshots {
+		if (ps != corev1.PodSucceeded) != expectSucceeded {
+			indices = append(indices, idx)
 		}
 	}
-
-	return
+	return NewIndexSet(indices...)
 }
 
 // JobRunPodLabelFilterOption generate a TweakListOptionsFunc for jobRun pods
@@ -381,11 +417,15 @@ func JobRunPodLabelFilterOption() internalinterfaces.TweakListOptionsFunc {
 	}
 }
 
-func (j *JobRun) IsRunningInDaemonMode() bool {
-	for _, envVar := range j.Spec.JobDefinitionSpec.Template.Containers[0].Env {
-		if envVar.Name == CEExecutionMode {
-			return envVar.Value == CEExecutionModeValue
-		}
+// GetPriority returns the priority of the jobRun, 0 if it's not set.
+func (j *JobRun) GetPriority() int32 {
+	if j.Spec.Priority == nil {
+		return 0
 	}
-	return false
+	return *j.Spec.Priority
+}
+
+// IsRunningInDaemonMode returns true if the jobRun runs in daemon mode, see JobDefinitionSpec.GetExecutionMode.
+func (j *JobRun) IsRunningInDaemonMode() bool {
+	return j.Spec.JobDefinitionSpec.IsDaemon()
 }
@@ -0,0 +1,58 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestUpdateStatusCountsParallelism(t *testing.T) {
+	tests := []struct {
+		name          string
+		parallelism   *int64
+		total         int64
+		pods          []corev1.PodPhase
+		wantRequested int64
+		wantThrottled int64
+	}{
+		{name: "no parallelism", total: 5, pods: []corev1.PodPhase{corev1.PodRunning}, wantRequested: 4},
+		{name: "below parallelism", parallelism: pointer.Int64(5), total: 4, pods: []corev1.PodPhase{corev1.PodRunning}, wantRequested: 3},
+		{name: "at parallelism", parallelism: pointer.Int64(3), total: 3, pods: []corev1.PodPhase{corev1.PodRunning}, wantRequested: 2},
+		{name: "one above parallelism", parallelism: pointer.Int64(3), total: 4, pods: []corev1.PodPhase{corev1.PodRunning}, wantRequested: 2, wantThrottled: 1},
+		{name: "none observed", parallelism: pointer.Int64(2), total: 5, wantRequested: 2, wantThrottled: 3},
+		{name: "pending and unknown are active", parallelism: pointer.Int64(3), total: 5,
+			pods: []corev1.PodPhase{corev1.PodPending, corev1.PodUnknown}, wantRequested: 1, wantThrottled: 2},
+		{name: "finished pods free slots", parallelism: pointer.Int64(2), total: 4,
+			pods: []corev1.PodPhase{corev1.PodSucceeded, corev1.PodFailed}, wantRequested: 2},
+		{name: "active beyond parallelism", parallelism: pointer.Int64(1), total: 5,
+			pods: []corev1.PodPhase{corev1.PodRunning, corev1.PodRunning, corev1.PodRunning}, wantThrottled: 2},
+		{name: "all observed", parallelism: pointer.Int64(1), total: 2, pods: []corev1.PodPhase{corev1.PodRunning, corev1.PodPending}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jr := &JobRun{}
+			jr.Spec.JobDefinitionSpec.Parallelism = tt.parallelism
+			// Counts of an earlier update are replaced.
+			jr.Status.Requested, jr.Status.Throttled = 7, 7
+
+			jr.UpdateStatusCounts(tt.total, tt.pods)
+			if jr.Status.Requested != tt.wantRequested || jr.Status.Throttled != tt.wantThrottled {
+				t.Errorf("requested, throttled = %d, %d, want %d, %d",
+					jr.Status.Requested, jr.Status.Throttled, tt.wantRequested, tt.wantThrottled)
+			}
+			s := jr.Status
+			if counted := s.Unknown + s.Pending + s.Running + s.Succeeded + s.Failed + s.Requested + s.Throttled; counted != tt.total {
+				t.Errorf("the counts add up to %d, want %d", counted, tt.total)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+)
+
+var _ apis.Validatable = (*JobRun)(nil)
+
+// Validate validates JobRun.
+func (jr *JobRun) Validate(ctx context.Context) *apis.FieldError {
+	errs := apis.ValidateObjectMetadata(jr.GetObjectMeta()).ViaField("metadata")
+	return errs.Also(jr.Spec.Validate(ctx).ViaField("spec"))
+}
+
+// Validate validates JobRunSpec.
+// A standalone jobRun must specify a complete template, while a jobRun that
+// refers to a jobDefinition may leave it empty to inherit the template from there.
+func (js *JobRunSpec) Validate(ctx context.Context) *apis.FieldError {
+	var errs *apis.FieldError
+	// The ref must be a valid jobDefinition name, see apis.ValidateObjectMetadata.
+	if js.JobDefinitionRef != "" {
+		for _, msg := range validation.IsDNS1035Label(js.JobDefinitionRef) {
+			errs = errs.Also(apis.ErrInvalidValue(js.JobDefinitionRef, "jobDefinitionRef", msg))
+		}
+	}
+
+	jds := &js.JobDefinitionSpec
+	errs = errs.Also(jds.validateFields(ctx).ViaField("jobDefinitionSpec"))
+	partial := js.RequiresDefaultingFromJobDefinition()
+	return errs.Also(jds.Template.validate(ctx, partial).ViaField("jobDefinitionSpec", "template"))
+}
@@ -0,0 +1,118 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package v1beta1
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestJobRunValidate(t *testing.T) {
+	container := []corev1.Container{{Image: "icr.io/codeengine/helloworld"}}
+	tests := []struct {
+		name string
+		spec JobRunSpec
+		want []string
+	}{{
+		name: "standalone",
+		spec: JobRunSpec{JobDefinitionSpec: JobDefinitionSpec{Template: JobPodTemplate{Containers: container}}},
+	}, {
+		name: "standalone without containers",
+		spec: JobRunSpec{},
+		want: []string{"spec.jobDefinitionSpec.template.containers"},
+	}, {
+		name: "standalone container without image",
+		spec: JobRunSpec{JobDefinitionSpec: JobDefinitionSpec{Template: JobPodTemplate{Containers: []corev1.Container{{}}}}},
+		want: []string{"spec.jobDefinitionSpec.template.containers[0].image"},
+	}, {
+		name: "ref without template",
+		spec: JobRunSpec{JobDefinitionRef: "jd"},
+	}, {
+		name: "ref with a partial container",
+		spec: JobRunSpec{
+			JobDefinitionRef:  "jd",
+			JobDefinitionSpec: JobDefinitionSpec{Template: JobPodTemplate{Containers: []corev1.Container{{Args: []string{"-v"}}}}},
+		},
+	}, {
+		// A DNS-1123 subdomain, but no valid jobDefinition name.
+		name: "ref with a dot",
+		spec: JobRunSpec{JobDefinitionRef: "jd.v2"},
+		want: []string{"spec.jobDefinitionRef"},
+	}, {
+		name: "ref starting with a digit",
+		spec: JobRunSpec{JobDefinitionRef: "1jd"},
+		want: []string{"spec.jobDefinitionRef"},
+	}, {
+		name: "ref with invalid overrides",
+		spec: JobRunSpec{
+			JobDefinitionRef: "jd",
+			JobDefinitionSpec: JobDefinitionSpec{
+				ArraySpec:        pointer.String("3-1"),
+				MaxExecutionTime: pointer.Int64(-5),
+			},
+		},
+		want: []string{"spec.jobDefinitionSpec.arraySpec", "spec.jobDefinitionSpec.maxExecutionTime"},
+	}, {
+		// The volume may be inherited from the jobDefinition.
+		name: "ref with a mount of an inherited volume",
+		spec: JobRunSpec{
+			JobDefinitionRef: "jd",
+			JobDefinitionSpec: JobDefinitionSpec{Template: JobPodTemplate{Containers: []corev1.Container{{
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+			}}}},
+		},
+	}, {
+		name: "ref with a relative mount path",
+		spec: JobRunSpec{
+			JobDefinitionRef: "jd",
+			JobDefinitionSpec: JobDefinitionSpec{Template: JobPodTemplate{Containers: []corev1.Container{{
+				VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "data"}},
+			}}}},
+		},
+		want: []string{"spec.jobDefinitionSpec.template.containers[0].volumeMounts[0].mountPath"},
+	}, {
+		name: "standalone with a mount of an unknown volume",
+		spec: JobRunSpec{JobDefinitionSpec: JobDefinitionSpec{Template: JobPodTemplate{Containers: []corev1.Container{{
+			Image:        "icr.io/codeengine/helloworld",
+			VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+		}}}}},
+		want: []string{"spec.jobDefinitionSpec.template.containers[0].volumeMounts[0].name"},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jr := &JobRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}, Spec: tt.spec}
+			err := jr.Validate(context.Background())
+			if got := errorPaths(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() reported %v, want %v: %v", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestJobRunValidateMetadata(t *testing.T) {
+	spec := JobRunSpec{JobDefinitionRef: "jd"}
+	for _, tt := range []struct {
+		meta metav1.ObjectMeta
+		want []string
+	}{
+		{meta: metav1.ObjectMeta{GenerateName: "run-"}},
+		{meta: metav1.ObjectMeta{}, want: []string{"metadata.name"}},
+		{meta: metav1.ObjectMeta{Name: "Run"}, want: []string{"metadata.name"}},
+	} {
+		jr := &JobRun{ObjectMeta: tt.meta, Spec: spec}
+		if got := errorPaths(jr.Validate(context.Background())); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Validate() of %+v reported %v, want %v", tt.meta, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	cm "knative.dev/pkg/configmap"
+)
+
+const (
+	// DefaultsConfigName is the name of config map for the defaults.
+	DefaultsConfigName = "config-jobrun-defaults"
+
+	// DefaultArraySpec is the arraySpec used when neither the jobRun nor the config map sets it.
+	DefaultArraySpec = "0"
+	// DefaultRetryLimit is the retryLimit used when neither the jobRun nor the config map sets it.
+	DefaultRetryLimit int64 = 3
+	// DefaultMaxExecutionTime is the maxExecutionTime in seconds used when neither the jobRun nor the config map sets it.
+	DefaultMaxExecutionTime int64 = 7200
+)
+
+// ArraySpecValidator validates the array-spec of the config map in index notation, e.g. "0-99".
+// It's set by the v1beta1 API package, which defines the notation and depends on this package.
+var ArraySpecValidator func(arraySpec string) error
+
+// Defaults includes the default values to be populated by the webhook or the client.
+type Defaults struct {
+	// ArraySpec is the default arraySpec of a standalone jobRun.
+	ArraySpec string
+
+	// RetryLimit is the default retryLimit of a standalone jobRun.
+	RetryLimit int64
+
+	// MaxExecutionTime is the default maxExecutionTime of a standalone jobRun.
+	MaxExecutionTime int64
+}
+
+// NewDefaultsConfigFromMap creates a Defaults from the supplied Map.
+// Keys missing from the map keep their built-in defaults.
+func NewDefaultsConfigFromMap(data map[string]string) (*Defaults, error) {
+	nc := defaultDefaultsConfig()
+
+	if err := cm.Parse(data,
+		cm.AsString("array-spec", &nc.ArraySpec),
+		cm.AsInt64("retry-limit", &nc.RetryLimit),
+		cm.AsInt64("max-execution-time", &nc.MaxExecutionTime),
+	); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(nc.ArraySpec) == "" {
+		return nil, fmt.Errorf("array-spec must not be empty")
+	}
+	if ArraySpecValidator != nil {
+		if err := ArraySpecValidator(nc.ArraySpec); err != nil {
+			return nil, fmt.Errorf("invalid array-spec: %w", err)
+		}
+	}
+	if nc.RetryLimit < 0 {
+		return nil, fmt.Errorf("retry-limit must not be negative, was: %d", nc.RetryLimit)
+	}
+	if nc.MaxExecutionTime <= 0 {
+		return nil, fmt.Errorf("max-execution-time must be a positive integer, was: %d", nc.MaxExecutionTime)
+	}
+
+	return nc, nil
+}
+
+// NewDefaultsConfigFromConfigMap creates a Defaults from the supplied configMap.
+func NewDefaultsConfigFromConfigMap(config *corev1.ConfigMap) (*Defaults, error) {
+	return NewDefaultsConfigFromMap(config.Data)
+}
+
+func defaultDefaultsConfig() *Defaults {
+	return &Defaults{
+		ArraySpec:        DefaultArraySpec,
+		RetryLimit:       DefaultRetryLimit,
+		MaxExecutionTime: DefaultMaxExecutionTime,
+	}
+}
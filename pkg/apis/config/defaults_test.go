@@ -0,0 +1,87 @@
+/*******************************************************************************
+ * Licensed Materials - Property of IBM
+ * IBM Cloud Code Engine, 5900-AB0
+ * © Copyright IBM Corp. 2020
+ * US Government Users Restricted Rights - Use, duplication or
+ * disclosure restricted by GSA ADP Schedule Contract with IBM Corp.
+ ******************************************************************************/
+
+package config_test
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	// Registers the validation of the array-spec.
+	_ "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/config"
+)
+
+func TestNewDefaultsConfigFromMap(t *testing.T) {
+	builtIn := config.Defaults{
+		ArraySpec:        config.DefaultArraySpec,
+		RetryLimit:       config.DefaultRetryLimit,
+		MaxExecutionTime: config.DefaultMaxExecutionTime,
+	}
+	tests := []struct {
+		name string
+		data map[string]string
+		want config.Defaults
+	}{{
+		name: "empty",
+		want: builtIn,
+	}, {
+		name: "all keys",
+		data: map[string]string{"array-spec": "0-9,20", "retry-limit": "0", "max-execution-time": "60"},
+		want: config.Defaults{ArraySpec: "0-9,20", RetryLimit: 0, MaxExecutionTime: 60},
+	}, {
+		name: "unknown keys",
+		data: map[string]string{"_example": "ignored"},
+		want: builtIn,
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := config.NewDefaultsConfigFromMap(tt.data)
+			if err != nil {
+				t.Fatalf("NewDefaultsConfigFromMap: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("NewDefaultsConfigFromMap() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDefaultsConfigFromMapErrors(t *testing.T) {
+	for _, data := range []map[string]string{
+		{"array-spec": ""},
+		{"array-spec": " "},
+		{"array-spec": "1-x"},
+		{"array-spec": "7-3"},
+		{"array-spec": "0-1000000"},
+		{"array-spec": "10000000"},
+		{"retry-limit": "-1"},
+		{"retry-limit": "three"},
+		{"max-execution-time": "0"},
+		{"max-execution-time": "1h"},
+	} {
+		if got, err := config.NewDefaultsConfigFromMap(data); err == nil {
+			t.Errorf("NewDefaultsConfigFromMap(%v) = %+v, want an error", data, *got)
+		}
+	}
+}
+
+func TestNewDefaultsConfigFromConfigMap(t *testing.T) {
+	got, err := config.NewDefaultsConfigFromConfigMap(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: config.DefaultsConfigName},
+		Data:       map[string]string{"array-spec": "1-3"},
+	})
+	if err != nil {
+		t.Fatalf("NewDefaultsConfigFromConfigMap: %v", err)
+	}
+	if got.ArraySpec != "1-3" || got.RetryLimit != config.DefaultRetryLimit {
+		t.Errorf("NewDefaultsConfigFromConfigMap() = %+v", *got)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+// JobDefinitionBuilder builds a JobDefinition step by step.
+// The steps it shares with JobRunBuilder set the settings the jobRuns of the
+// jobDefinition inherit unless they specify their own.
+type JobDefinitionBuilder struct {
+	jobDefinition *v1beta1.JobDefinition
+	spec          specBuilder
+}
+
+// NewJobDefinition starts building a jobDefinition with the given name.
+func NewJobDefinition(name string) *JobDefinitionBuilder {
+	return ForJobDefinition(&v1beta1.JobDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	})
+}
+
+// ForJobDefinition continues building a copy of the given jobDefinition.
+func ForJobDefinition(jd *v1beta1.JobDefinition) *JobDefinitionBuilder {
+	jd = jd.DeepCopy()
+	return &JobDefinitionBuilder{
+		jobDefinition: jd,
+		spec: specBuilder{
+			spec: &jd.Spec,
+			path: []string{"spec"},
+		},
+	}
+}
+
+// Namespace sets the namespace of the jobDefinition.
+func (b *JobDefinitionBuilder) Namespace(namespace string) *JobDefinitionBuilder {
+	b.jobDefinition.Namespace = namespace
+	return b
+}
+
+// Label sets a label of the jobDefinition.
+func (b *JobDefinitionBuilder) Label(key, value string) *JobDefinitionBuilder {
+	if err := validateLabel(key, value); err != nil {
+		b.spec.errs = b.spec.errs.Also(err)
+		return b
+	}
+	if b.jobDefinition.Labels == nil {
+		b.jobDefinition.Labels = map[string]string{}
+	}
+	b.jobDefinition.Labels[key] = value
+	return b
+}
+
+// Annotation sets an annotation of the jobDefinition.
+func (b *JobDefinitionBuilder) Annotation(key, value string) *JobDefinitionBuilder {
+	if err := validateAnnotation(key); err != nil {
+		b.spec.errs = b.spec.errs.Also(err)
+		return b
+	}
+	if b.jobDefinition.Annotations == nil {
+		b.jobDefinition.Annotations = map[string]string{}
+	}
+	b.jobDefinition.Annotations[key] = value
+	return b
+}
+
+// Image is like JobRunBuilder.Image.
+func (b *JobDefinitionBuilder) Image(image string) *JobDefinitionBuilder {
+	b.spec.image(image)
+	return b
+}
+
+// ContainerName is like JobRunBuilder.ContainerName.
+func (b *JobDefinitionBuilder) ContainerName(name string) *JobDefinitionBuilder {
+	b.spec.containerName(name)
+	return b
+}
+
+// Sidecar is like JobRunBuilder.Sidecar.
+func (b *JobDefinitionBuilder) Sidecar(c corev1.Container) *JobDefinitionBuilder {
+	b.spec.sidecar(c)
+	return b
+}
+
+// InitContainer is like JobRunBuilder.InitContainer.
+func (b *JobDefinitionBuilder) InitContainer(c corev1.Container) *JobDefinitionBuilder {
+	b.spec.initContainer(c)
+	return b
+}
+
+// Command is like JobRunBuilder.Command.
+func (b *JobDefinitionBuilder) Command(command ...string) *JobDefinitionBuilder {
+	b.spec.command(command)
+	return b
+}
+
+// Args is like JobRunBuilder.Args.
+func (b *JobDefinitionBuilder) Args(args ...string) *JobDefinitionBuilder {
+	b.spec.args(args)
+	return b
+}
+
+// Env is like JobRunBuilder.Env.
+func (b *JobDefinitionBuilder) Env(name, value string) *JobDefinitionBuilder {
+	b.spec.env(name, value)
+	return b
+}
+
+// Resources is like JobRunBuilder.Resources.
+func (b *JobDefinitionBuilder) Resources(resources corev1.ResourceRequirements) *JobDefinitionBuilder {
+	b.spec.resources(resources)
+	return b
+}
+
+// ServiceAccount is like JobRunBuilder.ServiceAccount.
+func (b *JobDefinitionBuilder) ServiceAccount(name string) *JobDefinitionBuilder {
+	b.spec.serviceAccount(name)
+	return b
+}
+
+// ImagePullSecret is like JobRunBuilder.ImagePullSecret.
+func (b *JobDefinitionBuilder) ImagePullSecret(name string) *JobDefinitionBuilder {
+	b.spec.imagePullSecret(name)
+	return b
+}
+
+// Volume is like JobRunBuilder.Volume.
+func (b *JobDefinitionBuilder) Volume(v corev1.Volume) *JobDefinitionBuilder {
+	b.spec.volume(v)
+	return b
+}
+
+// Mount is like JobRunBuilder.Mount.
+func (b *JobDefinitionBuilder) Mount(volume, mountPath string) *JobDefinitionBuilder {
+	b.spec.mount(volume, mountPath)
+	return b
+}
+
+// NodeSelector is like JobRunBuilder.NodeSelector.
+func (b *JobDefinitionBuilder) NodeSelector(key, value string) *JobDefinitionBuilder {
+	b.spec.nodeSelector(key, value)
+	return b
+}
+
+// Toleration is like JobRunBuilder.Toleration.
+func (b *JobDefinitionBuilder) Toleration(t corev1.Toleration) *JobDefinitionBuilder {
+	b.spec.toleration(t)
+	return b
+}
+
+// Affinity is like JobRunBuilder.Affinity.
+func (b *JobDefinitionBuilder) Affinity(a *corev1.Affinity) *JobDefinitionBuilder {
+	b.spec.affinity(a)
+	return b
+}
+
+// SpreadIndices is like JobRunBuilder.SpreadIndices.
+func (b *JobDefinitionBuilder) SpreadIndices(topologyKey string, whenUnsatisfiable corev1.UnsatisfiableConstraintAction) *JobDefinitionBuilder {
+	b.spec.spreadIndices(topologyKey, whenUnsatisfiable)
+	return b
+}
+
+// Array is like JobRunBuilder.Array, it sets the indices of the jobRuns which specify none.
+func (b *JobDefinitionBuilder) Array(arraySpec string) *JobDefinitionBuilder {
+	b.spec.array(arraySpec)
+	return b
+}
+
+// Retries is like JobRunBuilder.Retries.
+func (b *JobDefinitionBuilder) Retries(limit int64) *JobDefinitionBuilder {
+	b.spec.retries(limit)
+	return b
+}
+
+// Backoff is like JobRunBuilder.Backoff.
+func (b *JobDefinitionBuilder) Backoff(initial, max time.Duration) *JobDefinitionBuilder {
+	b.spec.backoff(initial, max)
+	return b
+}
+
+// RetryBudget is like JobRunBuilder.RetryBudget.
+func (b *JobDefinitionBuilder) RetryBudget(budget v1beta1.RetryBudget) *JobDefinitionBuilder {
+	b.spec.retryBudget(budget)
+	return b
+}
+
+// NonRetryableExitCodes is like JobRunBuilder.NonRetryableExitCodes.
+func (b *JobDefinitionBuilder) NonRetryableExitCodes(codes ...int32) *JobDefinitionBuilder {
+	b.spec.nonRetryableExitCodes(codes)
+	return b
+}
+
+// Parallelism is like JobRunBuilder.Parallelism.
+func (b *JobDefinitionBuilder) Parallelism(n int64) *JobDefinitionBuilder {
+	b.spec.parallelism(n)
+	return b
+}
+
+// Timeout is like JobRunBuilder.Timeout.
+func (b *JobDefinitionBuilder) Timeout(d time.Duration) *JobDefinitionBuilder {
+	b.spec.timeout(d)
+	return b
+}
+
+// Daemon is like JobRunBuilder.Daemon.
+func (b *JobDefinitionBuilder) Daemon() *JobDefinitionBuilder {
+	b.spec.daemon()
+	return b
+}
+
+// Err returns the errors of the steps so far.
+func (b *JobDefinitionBuilder) Err() error {
+	return toError(b.spec.errs)
+}
+
+// Build validates the jobDefinition and returns it.
+// The returned jobDefinition is a copy, so the builder may be reused for further jobDefinitions.
+// Like JobRunBuilder.Build, it sets the CE_EXECUTION_MODE environment variable in daemon mode.
+func (b *JobDefinitionBuilder) Build() (*v1beta1.JobDefinition, error) {
+	if err := toError(b.spec.errs.Also(b.jobDefinition.Validate(context.Background()))); err != nil {
+		return nil, err
+	}
+	jd := b.jobDefinition.DeepCopy()
+	jd.Spec.ExecutionModeToEnv()
+	return jd, nil
+}
+
+// MustBuild is like Build but panics if the jobDefinition is invalid, e.g. for tests.
+func (b *JobDefinitionBuilder) MustBuild() *v1beta1.JobDefinition {
+	jd, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return jd
+}
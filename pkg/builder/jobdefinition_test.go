@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+func TestJobDefinitionBuild(t *testing.T) {
+	got, err := NewJobDefinition("def").
+		Namespace("ns").
+		Label("team", "batch").
+		Image("busybox").
+		ContainerName("main").
+		Env("GREETING", "hello").
+		Sidecar(corev1.Container{Name: "proxy", Image: "proxy"}).
+		Array("0-99").
+		Retries(3).
+		Timeout(90 * time.Second).
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	want := &v1beta1.JobDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "def", Namespace: "ns", Labels: map[string]string{"team": "batch"}},
+		Spec: v1beta1.JobDefinitionSpec{
+			ArraySpec:        pointer.String("0-99"),
+			RetryLimit:       pointer.Int64(3),
+			MaxExecutionTime: pointer.Int64(90),
+			Template: v1beta1.JobPodTemplate{
+				Containers: []corev1.Container{
+					{Name: "main", Image: "busybox", Env: []corev1.EnvVar{{Name: "GREETING", Value: "hello"}}},
+					{Name: "proxy", Image: "proxy"},
+				},
+			},
+		},
+	}
+	if !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("Build() = %+v, want %+v", got, want)
+	}
+}
+
+func TestJobDefinitionStepErrors(t *testing.T) {
+	b := NewJobDefinition("def").Image("busybox").
+		Mount("data", "data").
+		Label("key", "bad value!").
+		Parallelism(-1)
+
+	want := []string{"metadata.labels.key", "spec.parallelism", "spec.template.containers[0].volumeMounts[0].mountPath"}
+	paths := errorPaths(t, b.Err())
+	if len(paths) != len(want) {
+		t.Fatalf("Err() has the paths %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("Err() has the paths %v, want %v", paths, want)
+			break
+		}
+	}
+	if _, err := b.Build(); err == nil {
+		t.Error("Build succeeded after invalid steps")
+	}
+}
+
+func TestJobDefinitionBuildValidates(t *testing.T) {
+	// A jobDefinition needs a container.
+	if _, err := NewJobDefinition("def").Array("1-5").Build(); err == nil {
+		t.Error("Build succeeded without a container")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustBuild didn't panic without a container")
+		}
+	}()
+	NewJobDefinition("def").MustBuild()
+}
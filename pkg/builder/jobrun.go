@@ -0,0 +1,282 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"knative.dev/pkg/apis"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+// JobRunBuilder builds a JobRun step by step.
+type JobRunBuilder struct {
+	jobRun *v1beta1.JobRun
+	spec   specBuilder
+}
+
+// NewJobRun starts building a jobRun with the given name.
+func NewJobRun(name string) *JobRunBuilder {
+	return ForJobRun(&v1beta1.JobRun{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	})
+}
+
+// ForJobRun continues building a copy of the given jobRun, e.g. one read from a manifest.
+func ForJobRun(jr *v1beta1.JobRun) *JobRunBuilder {
+	jr = jr.DeepCopy()
+	return &JobRunBuilder{
+		jobRun: jr,
+		spec: specBuilder{
+			spec: &jr.Spec.JobDefinitionSpec,
+			path: []string{"spec", "jobDefinitionSpec"},
+		},
+	}
+}
+
+// Namespace sets the namespace of the jobRun.
+func (b *JobRunBuilder) Namespace(namespace string) *JobRunBuilder {
+	b.jobRun.Namespace = namespace
+	return b
+}
+
+// GenerateName lets the server generate the name of the jobRun from the given prefix.
+// It clears the name.
+func (b *JobRunBuilder) GenerateName(prefix string) *JobRunBuilder {
+	b.jobRun.Name = ""
+	b.jobRun.GenerateName = prefix
+	return b
+}
+
+// Label sets a label of the jobRun.
+func (b *JobRunBuilder) Label(key, value string) *JobRunBuilder {
+	if err := validateLabel(key, value); err != nil {
+		b.spec.errs = b.spec.errs.Also(err)
+		return b
+	}
+	b.jobRun.AddLabel(key, value, true)
+	return b
+}
+
+// Annotation sets an annotation of the jobRun.
+func (b *JobRunBuilder) Annotation(key, value string) *JobRunBuilder {
+	if err := validateAnnotation(key); err != nil {
+		b.spec.errs = b.spec.errs.Also(err)
+		return b
+	}
+	if b.jobRun.Annotations == nil {
+		b.jobRun.Annotations = map[string]string{}
+	}
+	b.jobRun.Annotations[key] = value
+	return b
+}
+
+// FromDefinition makes the jobRun inherit the settings it doesn't specify from the named jobDefinition.
+func (b *JobRunBuilder) FromDefinition(name string) *JobRunBuilder {
+	if msgs := validation.IsDNS1035Label(name); len(msgs) > 0 {
+		b.spec.errs = b.spec.errs.Also(apis.ErrInvalidValue(name, "jobDefinitionRef", msgs[0]).ViaField("spec"))
+		return b
+	}
+	b.jobRun.Spec.JobDefinitionRef = name
+	return b
+}
+
+// Priority sets the priority of the jobRun, which orders its submission by a queue.
+func (b *JobRunBuilder) Priority(priority int32) *JobRunBuilder {
+	b.jobRun.Spec.Priority = &priority
+	return b
+}
+
+// Image sets the image of the main container.
+func (b *JobRunBuilder) Image(image string) *JobRunBuilder {
+	b.spec.image(image)
+	return b
+}
+
+// ContainerName names the main container, which is required once there are sidecars.
+func (b *JobRunBuilder) ContainerName(name string) *JobRunBuilder {
+	b.spec.containerName(name)
+	return b
+}
+
+// Sidecar adds a named container which runs next to the main container, e.g. a proxy.
+// Sidecars don't affect the outcome of an index.
+func (b *JobRunBuilder) Sidecar(c corev1.Container) *JobRunBuilder {
+	b.spec.sidecar(c)
+	return b
+}
+
+// InitContainer adds a named init container, which runs before the containers are started.
+func (b *JobRunBuilder) InitContainer(c corev1.Container) *JobRunBuilder {
+	b.spec.initContainer(c)
+	return b
+}
+
+// Command sets the command of the main container.
+func (b *JobRunBuilder) Command(command ...string) *JobRunBuilder {
+	b.spec.command(command)
+	return b
+}
+
+// Args sets the arguments of the main container.
+func (b *JobRunBuilder) Args(args ...string) *JobRunBuilder {
+	b.spec.args(args)
+	return b
+}
+
+// Env sets an environment variable of the main container.
+func (b *JobRunBuilder) Env(name, value string) *JobRunBuilder {
+	b.spec.env(name, value)
+	return b
+}
+
+// Resources sets the compute resources of the main container.
+func (b *JobRunBuilder) Resources(resources corev1.ResourceRequirements) *JobRunBuilder {
+	b.spec.resources(resources)
+	return b
+}
+
+// ServiceAccount sets the service account the pods run as.
+func (b *JobRunBuilder) ServiceAccount(name string) *JobRunBuilder {
+	b.spec.serviceAccount(name)
+	return b
+}
+
+// ImagePullSecret adds a secret used to pull the images.
+func (b *JobRunBuilder) ImagePullSecret(name string) *JobRunBuilder {
+	b.spec.imagePullSecret(name)
+	return b
+}
+
+// Volume adds a volume, replacing the one of the same name.
+// Secret, configMap, emptyDir and persistentVolumeClaim volumes are supported.
+func (b *JobRunBuilder) Volume(v corev1.Volume) *JobRunBuilder {
+	b.spec.volume(v)
+	return b
+}
+
+// Mount mounts the volume of the given name into the main container at mountPath.
+func (b *JobRunBuilder) Mount(volume, mountPath string) *JobRunBuilder {
+	b.spec.mount(volume, mountPath)
+	return b
+}
+
+// NodeSelector requires the pods to be scheduled on nodes with the given label.
+func (b *JobRunBuilder) NodeSelector(key, value string) *JobRunBuilder {
+	b.spec.nodeSelector(key, value)
+	return b
+}
+
+// Toleration adds a toleration, which allows the pods to be scheduled onto nodes with matching taints.
+func (b *JobRunBuilder) Toleration(t corev1.Toleration) *JobRunBuilder {
+	b.spec.toleration(t)
+	return b
+}
+
+// Affinity sets the node affinity and pod (anti-)affinity of the pods.
+func (b *JobRunBuilder) Affinity(a *corev1.Affinity) *JobRunBuilder {
+	b.spec.affinity(a)
+	return b
+}
+
+// SpreadIndices spreads the pods of the jobRun evenly across the domains of topologyKey, e.g. corev1.LabelTopologyZone.
+// Once a constraint is set, the default spreading across zones and nodes no longer applies.
+func (b *JobRunBuilder) SpreadIndices(topologyKey string, whenUnsatisfiable corev1.UnsatisfiableConstraintAction) *JobRunBuilder {
+	b.spec.spreadIndices(topologyKey, whenUnsatisfiable)
+	return b
+}
+
+// Array sets the indices of the jobRun in index notation, e.g. "0-99".
+func (b *JobRunBuilder) Array(arraySpec string) *JobRunBuilder {
+	b.spec.array(arraySpec)
+	return b
+}
+
+// Retries sets the number of retries of an index before it's marked failed.
+func (b *JobRunBuilder) Retries(limit int64) *JobRunBuilder {
+	b.spec.retries(limit)
+	return b
+}
+
+// Backoff delays the first retry of an index by initial, and every further retry by twice
+// the previous delay, up to max, which must not exceed one day. Both are rounded up to full seconds.
+func (b *JobRunBuilder) Backoff(initial, max time.Duration) *JobRunBuilder {
+	b.spec.backoff(initial, max)
+	return b
+}
+
+// RetryBudget sets whether the retries apply to every index or to the jobRun as a whole.
+func (b *JobRunBuilder) RetryBudget(budget v1beta1.RetryBudget) *JobRunBuilder {
+	b.spec.retryBudget(budget)
+	return b
+}
+
+// NonRetryableExitCodes marks indices failed without retrying them if their main container exits with one of the codes.
+func (b *JobRunBuilder) NonRetryableExitCodes(codes ...int32) *JobRunBuilder {
+	b.spec.nonRetryableExitCodes(codes)
+	return b
+}
+
+// Parallelism limits the number of indices of the jobRun running at the same time.
+func (b *JobRunBuilder) Parallelism(n int64) *JobRunBuilder {
+	b.spec.parallelism(n)
+	return b
+}
+
+// Timeout sets the maximum execution time of the jobRun, rounded up to full seconds.
+func (b *JobRunBuilder) Timeout(d time.Duration) *JobRunBuilder {
+	b.spec.timeout(d)
+	return b
+}
+
+// Daemon runs the jobRun in daemon mode, in which its pods are expected to run until deleted.
+func (b *JobRunBuilder) Daemon() *JobRunBuilder {
+	b.spec.daemon()
+	return b
+}
+
+// Err returns the errors of the steps so far.
+func (b *JobRunBuilder) Err() error {
+	return toError(b.spec.errs)
+}
+
+// Build validates the jobRun and returns it.
+// The returned jobRun is a copy, so the builder may be reused for further jobRuns.
+// In daemon mode its main container also sets the CE_EXECUTION_MODE environment variable,
+// see v1beta1.JobDefinitionSpec.ExecutionModeToEnv.
+func (b *JobRunBuilder) Build() (*v1beta1.JobRun, error) {
+	if err := toError(b.spec.errs.Also(b.jobRun.Validate(context.Background()))); err != nil {
+		return nil, err
+	}
+	jr := b.jobRun.DeepCopy()
+	jr.Spec.JobDefinitionSpec.ExecutionModeToEnv()
+	return jr, nil
+}
+
+// MustBuild is like Build but panics if the jobRun is invalid, e.g. for tests.
+func (b *JobRunBuilder) MustBuild() *v1beta1.JobRun {
+	jr, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return jr
+}
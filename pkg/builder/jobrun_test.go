@@ -0,0 +1,239 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+// errorPaths returns the sorted paths of the field errors of err.
+func errorPaths(t *testing.T, err error) []string {
+	t.Helper()
+	var fe *apis.FieldError
+	if !errors.As(err, &fe) {
+		t.Fatalf("error %v isn't a field error", err)
+	}
+	var paths []string
+	for _, e := range fe.WrappedErrors() {
+		paths = append(paths, e.Paths...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestJobRunBuild(t *testing.T) {
+	resources := corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}}
+	proxy := corev1.Container{Name: "proxy", Image: "proxy"}
+	setup := corev1.Container{Name: "setup", Image: "setup"}
+
+	got, err := NewJobRun("run").
+		Namespace("ns").
+		Label("team", "batch").
+		Annotation("example.com/owner", "me").
+		FromDefinition("def").
+		Priority(5).
+		Image("busybox").
+		ContainerName("main").
+		Command("sh", "-c").
+		Args("echo $GREETING").
+		Env("GREETING", "hello").
+		Env("GREETING", "hi").
+		Resources(resources).
+		Sidecar(proxy).
+		InitContainer(setup).
+		Volume(corev1.Volume{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}).
+		Mount("data", "/data").
+		ServiceAccount("runner").
+		ImagePullSecret("registry").
+		NodeSelector("kubernetes.io/arch", "amd64").
+		Array("0-9").
+		Retries(2).
+		Backoff(1500*time.Millisecond, time.Minute).
+		NonRetryableExitCodes(2, 3).
+		Parallelism(4).
+		Timeout(time.Hour).
+		Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	want := &v1beta1.JobRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "run",
+			Namespace:   "ns",
+			Labels:      map[string]string{"team": "batch"},
+			Annotations: map[string]string{"example.com/owner": "me"},
+		},
+		Spec: v1beta1.JobRunSpec{
+			JobDefinitionRef: "def",
+			Priority:         pointer.Int32(5),
+			JobDefinitionSpec: v1beta1.JobDefinitionSpec{
+				ArraySpec:        pointer.String("0-9"),
+				RetryLimit:       pointer.Int64(2),
+				MaxExecutionTime: pointer.Int64(3600),
+				Parallelism:      pointer.Int64(4),
+				RetryPolicy: &v1beta1.RetryPolicy{
+					BackoffSeconds:        2,
+					MaxBackoffSeconds:     60,
+					NonRetryableExitCodes: []int32{2, 3},
+				},
+				Template: v1beta1.JobPodTemplate{
+					InitContainers: []corev1.Container{setup},
+					Containers: []corev1.Container{{
+						Name:         "main",
+						Image:        "busybox",
+						Command:      []string{"sh", "-c"},
+						Args:         []string{"echo $GREETING"},
+						Env:          []corev1.EnvVar{{Name: "GREETING", Value: "hi"}},
+						Resources:    resources,
+						VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+					}, proxy},
+					Volumes:            []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+					ServiceAccountName: "runner",
+					ImagePullSecrets:   []corev1.LocalObjectReference{{Name: "registry"}},
+					NodeSelector:       map[string]string{"kubernetes.io/arch": "amd64"},
+				},
+			},
+		},
+	}
+	if !equality.Semantic.DeepEqual(got, want) {
+		t.Errorf("Build() = %+v, want %+v", got, want)
+	}
+}
+
+func TestJobRunBuildFromManifest(t *testing.T) {
+	jr := &v1beta1.JobRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "run"},
+		Spec:       v1beta1.JobRunSpec{JobDefinitionRef: "def"},
+	}
+	b := ForJobRun(jr).GenerateName("def-").Array("3")
+
+	got, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if got.Name != "" || got.GenerateName != "def-" || *got.Spec.JobDefinitionSpec.ArraySpec != "3" {
+		t.Errorf("Build() = %+v, want a generated name and arraySpec 3", got.ObjectMeta)
+	}
+	if jr.Spec.JobDefinitionSpec.ArraySpec != nil {
+		t.Error("the builder modified the given jobRun")
+	}
+
+	// The builder may be reused, the built jobRun is a copy.
+	got.Spec.JobDefinitionSpec.ArraySpec = pointer.String("4")
+	again := b.Parallelism(2).MustBuild()
+	if *again.Spec.JobDefinitionSpec.ArraySpec != "3" || *again.Spec.JobDefinitionSpec.Parallelism != 2 {
+		t.Errorf("the reused builder built %+v", again.Spec.JobDefinitionSpec)
+	}
+}
+
+func TestJobRunBuildDaemon(t *testing.T) {
+	got := NewJobRun("run").Image("busybox").Daemon().MustBuild()
+	spec := got.Spec.JobDefinitionSpec
+	if spec.ExecutionMode != v1beta1.ExecutionModeDaemon {
+		t.Errorf("executionMode = %q, want daemon", spec.ExecutionMode)
+	}
+	// Older servers detect daemon mode by the environment variable.
+	if env := spec.Template.Containers[0].Env; len(env) != 1 || env[0].Name != v1beta1.CEExecutionMode || env[0].Value != v1beta1.CEExecutionModeValue {
+		t.Errorf("env = %v, want %s=%s", env, v1beta1.CEExecutionMode, v1beta1.CEExecutionModeValue)
+	}
+}
+
+func TestJobRunBuildDaemonOverridesEnv(t *testing.T) {
+	jr := NewJobRun("run").Image("busybox").Env(v1beta1.CEExecutionMode, "TASK").MustBuild()
+	if jr.Spec.JobDefinitionSpec.IsDaemon() {
+		t.Fatal("the jobRun is in daemon mode before Daemon")
+	}
+
+	got := ForJobRun(jr).Daemon().MustBuild()
+	if env := got.Spec.JobDefinitionSpec.Template.Containers[0].Env; len(env) != 1 || env[0].Value != v1beta1.CEExecutionModeValue {
+		t.Errorf("env = %v, want %s=%s", env, v1beta1.CEExecutionMode, v1beta1.CEExecutionModeValue)
+	}
+}
+
+func TestJobRunStepErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		step func(b *JobRunBuilder)
+		want string
+	}{
+		{name: "container name", step: func(b *JobRunBuilder) { b.ContainerName("Main_") }, want: "spec.jobDefinitionSpec.template.containers[0].name"},
+		{name: "sidecar name", step: func(b *JobRunBuilder) { b.Sidecar(corev1.Container{Name: "bad name"}) }, want: "spec.jobDefinitionSpec.template.containers[1].name"},
+		{name: "unnamed init container", step: func(b *JobRunBuilder) { b.InitContainer(corev1.Container{Image: "setup"}) }, want: "spec.jobDefinitionSpec.template.initContainers[0].name"},
+		{name: "empty image", step: func(b *JobRunBuilder) { b.Image("") }, want: "spec.jobDefinitionSpec.template.containers[0].image"},
+		{name: "env name", step: func(b *JobRunBuilder) { b.Env("1BAD", "x") }, want: "spec.jobDefinitionSpec.template.containers[0].env[1BAD].name"},
+		{name: "service account", step: func(b *JobRunBuilder) { b.ServiceAccount("Bad_SA") }, want: "spec.jobDefinitionSpec.template.serviceAccountName"},
+		{name: "pull secret", step: func(b *JobRunBuilder) { b.ImagePullSecret("") }, want: "spec.jobDefinitionSpec.template.imagePullSecrets[0].name"},
+		{name: "volume name", step: func(b *JobRunBuilder) { b.Volume(corev1.Volume{}) }, want: "spec.jobDefinitionSpec.template.volumes[0].name"},
+		{name: "relative mount path", step: func(b *JobRunBuilder) { b.Mount("data", "relative/path") }, want: "spec.jobDefinitionSpec.template.containers[0].volumeMounts[0].mountPath"},
+		{name: "empty mount path", step: func(b *JobRunBuilder) { b.Mount("data", "") }, want: "spec.jobDefinitionSpec.template.containers[0].volumeMounts[0].mountPath"},
+		{name: "mounted volume name", step: func(b *JobRunBuilder) { b.Mount("Bad_Vol", "/data") }, want: "spec.jobDefinitionSpec.template.containers[0].volumeMounts[0].name"},
+		{name: "node selector", step: func(b *JobRunBuilder) { b.NodeSelector("bad key!", "v") }, want: "spec.jobDefinitionSpec.template.nodeSelector[bad key!]"},
+		{name: "toleration", step: func(b *JobRunBuilder) { b.Toleration(corev1.Toleration{Operator: "Bad"}) }, want: "spec.jobDefinitionSpec.template.tolerations[0].operator"},
+		{name: "spread constraint", step: func(b *JobRunBuilder) { b.SpreadIndices("", corev1.DoNotSchedule) }, want: "spec.jobDefinitionSpec.template.topologySpreadConstraints[0].topologyKey"},
+		{name: "array spec", step: func(b *JobRunBuilder) { b.Array("3-1") }, want: "spec.jobDefinitionSpec.arraySpec"},
+		{name: "retries", step: func(b *JobRunBuilder) { b.Retries(-1) }, want: "spec.jobDefinitionSpec.retryLimit"},
+		{name: "negative backoff", step: func(b *JobRunBuilder) { b.Backoff(-time.Second, 0) }, want: "spec.jobDefinitionSpec.retryPolicy.backoffSeconds"},
+		{name: "max backoff", step: func(b *JobRunBuilder) { b.Backoff(10*time.Second, time.Second) }, want: "spec.jobDefinitionSpec.retryPolicy.maxBackoffSeconds"},
+		{name: "backoff too long", step: func(b *JobRunBuilder) { b.Backoff(time.Second, 48*time.Hour) }, want: "spec.jobDefinitionSpec.retryPolicy.maxBackoffSeconds"},
+		{name: "exit code", step: func(b *JobRunBuilder) { b.NonRetryableExitCodes(0) }, want: "spec.jobDefinitionSpec.retryPolicy.nonRetryableExitCodes[0]"},
+		{name: "timeout", step: func(b *JobRunBuilder) { b.Timeout(0) }, want: "spec.jobDefinitionSpec.maxExecutionTime"},
+		{name: "parallelism", step: func(b *JobRunBuilder) { b.Parallelism(0) }, want: "spec.jobDefinitionSpec.parallelism"},
+		{name: "label key", step: func(b *JobRunBuilder) { b.Label("bad key!", "v") }, want: "metadata.labels"},
+		{name: "label value", step: func(b *JobRunBuilder) { b.Label("key", "bad value!") }, want: "metadata.labels.key"},
+		{name: "annotation key", step: func(b *JobRunBuilder) { b.Annotation("bad key!", "v") }, want: "metadata.annotations"},
+		{name: "jobDefinition", step: func(b *JobRunBuilder) { b.FromDefinition("Bad") }, want: "spec.jobDefinitionRef"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewJobRun("run").Image("busybox")
+			valid := b.MustBuild()
+			test.step(b)
+
+			if paths := errorPaths(t, b.Err()); len(paths) != 1 || paths[0] != test.want {
+				t.Errorf("Err() has the paths %v, want %s", paths, test.want)
+			}
+			if _, err := b.Build(); err == nil {
+				t.Fatal("Build succeeded after an invalid step")
+			}
+			// The invalid value wasn't applied.
+			if !equality.Semantic.DeepEqual(b.jobRun, valid) {
+				t.Errorf("the invalid step changed the jobRun to %+v", b.jobRun)
+			}
+		})
+	}
+}
+
+func TestJobRunBuildValidates(t *testing.T) {
+	// Every step is valid, but the jobRun lacks an image.
+	_, err := NewJobRun("run").ContainerName("main").Build()
+	if paths := errorPaths(t, err); len(paths) != 1 || paths[0] != "spec.jobDefinitionSpec.template.containers[0].image" {
+		t.Errorf("Build() failed at %v, want the missing image", paths)
+	}
+}
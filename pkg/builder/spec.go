@@ -0,0 +1,358 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder constructs JobRuns and JobDefinitions with a fluent API, e.g.
+//
+//	jr, err := builder.NewJobRun("name").
+//		FromDefinition("jd").
+//		Image("icr.io/codeengine/helloworld").
+//		Env("TARGET", "world").
+//		Array("0-99").
+//		Retries(2).
+//		Timeout(time.Hour).
+//		Build()
+//
+// Every step that takes a name, path or other constrained value validates it: an invalid
+// value is not applied and its error is reported by Err and Build, which validates the
+// complete resource in addition. Free-form values, such as the command, args and
+// resources, are applied as given.
+package builder
+
+import (
+	"path"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
+	"knative.dev/pkg/apis"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+// specBuilder implements the steps shared by JobRunBuilder and JobDefinitionBuilder.
+type specBuilder struct {
+	spec *v1beta1.JobDefinitionSpec
+	// path is the path of the spec in the resource, errors are reported relative to it.
+	path []string
+	// errs holds the errors of all steps, including those outside of the spec.
+	errs *apis.FieldError
+}
+
+func (b *specBuilder) addError(err *apis.FieldError) {
+	b.errs = b.errs.Also(err.ViaField(b.path...))
+}
+
+// addContainerError adds an error of the main container.
+func (b *specBuilder) addContainerError(err *apis.FieldError) {
+	i := 0
+	if c := b.spec.Template.GetMainContainer(); c != nil {
+		for j := range b.spec.Template.Containers {
+			if &b.spec.Template.Containers[j] == c {
+				i = j
+			}
+		}
+	}
+	b.addError(err.ViaFieldIndex("containers", i).ViaField("template"))
+}
+
+// container returns the main container of the template, which is created if necessary.
+func (b *specBuilder) container() *corev1.Container {
+	if c := b.spec.Template.GetMainContainer(); c != nil {
+		return c
+	}
+	b.spec.Template.Containers = append(b.spec.Template.Containers, corev1.Container{Name: b.spec.Template.MainContainer})
+	return b.spec.Template.GetMainContainer()
+}
+
+// containerName names the main container.
+func (b *specBuilder) containerName(name string) {
+	if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+		b.addContainerError(apis.ErrInvalidValue(name, "name", msgs[0]))
+		return
+	}
+	c := b.container()
+	c.Name = name
+	if b.spec.Template.MainContainer != "" {
+		b.spec.Template.MainContainer = name
+	}
+}
+
+// sidecar adds a container next to the main container, which is created first if necessary.
+func (b *specBuilder) sidecar(c corev1.Container) {
+	if msgs := validation.IsDNS1123Label(c.Name); len(msgs) > 0 {
+		b.addError(apis.ErrInvalidValue(c.Name, "name", msgs[0]).ViaFieldIndex("containers", len(b.spec.Template.Containers)).ViaField("template"))
+		return
+	}
+	b.container()
+	b.spec.Template.Containers = append(b.spec.Template.Containers, c)
+}
+
+func (b *specBuilder) initContainer(c corev1.Container) {
+	if msgs := validation.IsDNS1123Label(c.Name); len(msgs) > 0 {
+		b.addError(apis.ErrInvalidValue(c.Name, "name", msgs[0]).ViaFieldIndex("initContainers", len(b.spec.Template.InitContainers)).ViaField("template"))
+		return
+	}
+	b.spec.Template.InitContainers = append(b.spec.Template.InitContainers, c)
+}
+
+func (b *specBuilder) image(image string) {
+	if image == "" {
+		b.addContainerError(apis.ErrInvalidValue(image, "image", "must not be empty"))
+		return
+	}
+	b.container().Image = image
+}
+
+func (b *specBuilder) command(command []string) {
+	b.container().Command = command
+}
+
+func (b *specBuilder) args(args []string) {
+	b.container().Args = args
+}
+
+func (b *specBuilder) env(name, value string) {
+	if msgs := validation.IsEnvVarName(name); len(msgs) > 0 {
+		b.addContainerError(apis.ErrInvalidValue(name, "name", msgs[0]).ViaFieldKey("env", name))
+		return
+	}
+	setEnv(b.container(), name, value)
+}
+
+func (b *specBuilder) resources(resources corev1.ResourceRequirements) {
+	b.container().Resources = resources
+}
+
+func (b *specBuilder) serviceAccount(name string) {
+	if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		b.addError(apis.ErrInvalidValue(name, "serviceAccountName", msgs[0]).ViaField("template"))
+		return
+	}
+	b.spec.Template.ServiceAccountName = name
+}
+
+func (b *specBuilder) imagePullSecret(name string) {
+	if name == "" {
+		b.addError(apis.ErrMissingField("name").ViaFieldIndex("imagePullSecrets", len(b.spec.Template.ImagePullSecrets)).ViaField("template"))
+		return
+	}
+	b.spec.Template.ImagePullSecrets = append(b.spec.Template.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+}
+
+// volume adds a volume or replaces the one of the same name.
+func (b *specBuilder) volume(v corev1.Volume) {
+	if msgs := validation.IsDNS1123Label(v.Name); len(msgs) > 0 {
+		b.addError(apis.ErrInvalidValue(v.Name, "name", msgs[0]).ViaFieldIndex("volumes", len(b.spec.Template.Volumes)).ViaField("template"))
+		return
+	}
+	for i := range b.spec.Template.Volumes {
+		if b.spec.Template.Volumes[i].Name == v.Name {
+			b.spec.Template.Volumes[i] = v
+			return
+		}
+	}
+	b.spec.Template.Volumes = append(b.spec.Template.Volumes, v)
+}
+
+// mount mounts a volume into the main container.
+func (b *specBuilder) mount(volume, mountPath string) {
+	c := b.container()
+	if msgs := validation.IsDNS1123Label(volume); len(msgs) > 0 {
+		b.addContainerError(apis.ErrInvalidValue(volume, "name", msgs[0]).ViaFieldIndex("volumeMounts", len(c.VolumeMounts)))
+		return
+	}
+	if !path.IsAbs(mountPath) {
+		b.addContainerError(apis.ErrInvalidValue(mountPath, "mountPath", "must be an absolute path").ViaFieldIndex("volumeMounts", len(c.VolumeMounts)))
+		return
+	}
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{Name: volume, MountPath: mountPath})
+}
+
+func (b *specBuilder) nodeSelector(key, value string) {
+	if msgs := append(validation.IsQualifiedName(key), validation.IsValidLabelValue(value)...); len(msgs) > 0 {
+		b.addError(apis.ErrInvalidValue(value, apis.CurrentField, msgs[0]).ViaFieldKey("nodeSelector", key).ViaField("template"))
+		return
+	}
+	if b.spec.Template.NodeSelector == nil {
+		b.spec.Template.NodeSelector = map[string]string{}
+	}
+	b.spec.Template.NodeSelector[key] = value
+}
+
+func (b *specBuilder) toleration(t corev1.Toleration) {
+	if err := v1beta1.ValidateToleration(&t); err != nil {
+		b.addError(err.ViaFieldIndex("tolerations", len(b.spec.Template.Tolerations)).ViaField("template"))
+		return
+	}
+	b.spec.Template.Tolerations = append(b.spec.Template.Tolerations, t)
+}
+
+// affinity sets the affinity of the pods, nil removes it.
+func (b *specBuilder) affinity(a *corev1.Affinity) {
+	if a != nil {
+		if err := v1beta1.ValidateAffinity(a); err != nil {
+			b.addError(err.ViaField("template", "affinity"))
+			return
+		}
+	}
+	b.spec.Template.Affinity = a
+}
+
+// spreadIndices adds a constraint spreading the pods of the jobRun evenly across the domains of topologyKey.
+func (b *specBuilder) spreadIndices(topologyKey string, whenUnsatisfiable corev1.UnsatisfiableConstraintAction) {
+	c := corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       topologyKey,
+		WhenUnsatisfiable: whenUnsatisfiable,
+	}
+	if err := v1beta1.ValidateTopologySpreadConstraint(&c); err != nil {
+		b.addError(err.ViaFieldIndex("topologySpreadConstraints", len(b.spec.Template.TopologySpreadConstraints)).ViaField("template"))
+		return
+	}
+	b.spec.Template.TopologySpreadConstraints = append(b.spec.Template.TopologySpreadConstraints, c)
+}
+
+func (b *specBuilder) array(arraySpec string) {
+	if err := v1beta1.ValidateArraySpec(arraySpec); err != nil {
+		b.addError(err.ViaField("arraySpec"))
+		return
+	}
+	b.spec.ArraySpec = pointer.String(arraySpec)
+}
+
+func (b *specBuilder) retries(limit int64) {
+	if limit < 0 {
+		b.addError(apis.ErrInvalidValue(limit, "retryLimit", "must not be negative"))
+		return
+	}
+	b.spec.RetryLimit = pointer.Int64(limit)
+}
+
+// retryPolicy returns the retry policy of the spec, which is created if necessary.
+func (b *specBuilder) retryPolicy() *v1beta1.RetryPolicy {
+	if b.spec.RetryPolicy == nil {
+		b.spec.RetryPolicy = &v1beta1.RetryPolicy{}
+	}
+	return b.spec.RetryPolicy
+}
+
+// backoff sets the delay before the first retry and its upper bound, rounded up to full seconds.
+func (b *specBuilder) backoff(initial, max time.Duration) {
+	if initial < 0 {
+		b.addError(apis.ErrInvalidValue(initial.String(), "backoffSeconds", "must not be negative").ViaField("retryPolicy"))
+		return
+	}
+	if max < initial {
+		b.addError(apis.ErrInvalidValue(max.String(), "maxBackoffSeconds", "must not be less than backoffSeconds").ViaField("retryPolicy"))
+		return
+	}
+	if limit := time.Duration(v1beta1.MaxRetryBackoffSeconds) * time.Second; max > limit {
+		b.addError(apis.ErrOutOfBoundsValue(max.String(), "0s", limit.String(), "maxBackoffSeconds").ViaField("retryPolicy"))
+		return
+	}
+	p := b.retryPolicy()
+	p.BackoffSeconds = seconds(initial)
+	p.MaxBackoffSeconds = seconds(max)
+}
+
+func (b *specBuilder) retryBudget(budget v1beta1.RetryBudget) {
+	b.retryPolicy().Budget = budget
+}
+
+func (b *specBuilder) nonRetryableExitCodes(codes []int32) {
+	var p v1beta1.RetryPolicy
+	if b.spec.RetryPolicy != nil {
+		p.NonRetryableExitCodes = append(p.NonRetryableExitCodes, b.spec.RetryPolicy.NonRetryableExitCodes...)
+	}
+	p.NonRetryableExitCodes = append(p.NonRetryableExitCodes, codes...)
+	if err := p.Validate(); err != nil {
+		b.addError(err.ViaField("retryPolicy"))
+		return
+	}
+	b.retryPolicy().NonRetryableExitCodes = p.NonRetryableExitCodes
+}
+
+// timeout sets the maximum execution time, rounded up to full seconds.
+func (b *specBuilder) timeout(d time.Duration) {
+	if d <= 0 {
+		b.addError(apis.ErrInvalidValue(d.String(), "maxExecutionTime", "must be positive"))
+		return
+	}
+	b.spec.MaxExecutionTime = pointer.Int64(seconds(d))
+}
+
+func (b *specBuilder) parallelism(n int64) {
+	if n < 1 {
+		b.addError(apis.ErrInvalidValue(n, "parallelism", "must be positive"))
+		return
+	}
+	b.spec.Parallelism = pointer.Int64(n)
+}
+
+// seconds returns the duration in seconds, rounded up.
+func seconds(d time.Duration) int64 {
+	s := int64(d / time.Second)
+	if d%time.Second != 0 {
+		s++
+	}
+	return s
+}
+
+// daemon selects daemon mode, overriding the CE_EXECUTION_MODE environment variable of older specs.
+func (b *specBuilder) daemon() {
+	b.spec.ExecutionMode = v1beta1.ExecutionModeDaemon
+	b.spec.ExecutionModeToEnv()
+}
+
+// validateLabel validates a label at metadata.labels.
+func validateLabel(key, value string) *apis.FieldError {
+	var errs *apis.FieldError
+	if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+		errs = errs.Also(apis.ErrInvalidKeyName(key, apis.CurrentField, msgs...))
+	}
+	if msgs := validation.IsValidLabelValue(value); len(msgs) > 0 {
+		errs = errs.Also(apis.ErrInvalidValue(value, key, msgs...))
+	}
+	return errs.ViaField("metadata", "labels")
+}
+
+// validateAnnotation validates the key of an annotation at metadata.annotations.
+func validateAnnotation(key string) *apis.FieldError {
+	if msgs := validation.IsQualifiedName(key); len(msgs) > 0 {
+		return apis.ErrInvalidKeyName(key, apis.CurrentField, msgs...).ViaField("metadata", "annotations")
+	}
+	return nil
+}
+
+// setEnv sets the environment variable of the container, replacing its previous value.
+func setEnv(c *corev1.Container, name, value string) {
+	for i := range c.Env {
+		if c.Env[i].Name == name {
+			c.Env[i] = corev1.EnvVar{Name: name, Value: value}
+			return
+		}
+	}
+	c.Env = append(c.Env, corev1.EnvVar{Name: name, Value: value})
+}
+
+// toError converts field errors into an error, which is nil if there are none.
+func toError(errs *apis.FieldError) error {
+	if errs == nil {
+		return nil
+	}
+	return errs
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/testing"
+)
+
+// NewApplyClientset returns a clientset like NewSimpleClientset, which in addition handles
+// the Apply and ApplyStatus calls of the typed clients. Clientsets of NewSimpleClientset
+// fail these calls, as their object tracker rejects apply patches.
+//
+// The object tracker has no notion of field managers, so an apply configuration is merged
+// into the stored object like a JSON merge patch: the fields it sets are overwritten, lists
+// are replaced as a whole and conflicts are never reported. An object which doesn't exist
+// is created by Apply.
+func NewApplyClientset(objects ...runtime.Object) *Clientset {
+	cs := NewSimpleClientset(objects...)
+	cs.PrependReactor("patch", "*", applyReactor(cs.Tracker()))
+	return cs
+}
+
+// applyReactor handles apply patches of the main resource and of the status subresource.
+func applyReactor(tracker testing.ObjectTracker) testing.ReactionFunc {
+	return func(action testing.Action) (bool, runtime.Object, error) {
+		patchAction, ok := action.(testing.PatchAction)
+		if !ok || patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		gvr := patchAction.GetResource()
+		ns := patchAction.GetNamespace()
+		name := patchAction.GetName()
+		status := patchAction.GetSubresource() == "status"
+
+		patch, gvk, err := applyPatch(patchAction.GetPatch(), status)
+		if err != nil {
+			return true, nil, apierrors.NewBadRequest(err.Error())
+		}
+
+		existing, err := tracker.Get(gvr, ns, name)
+		if apierrors.IsNotFound(err) && !status {
+			obj, err := scheme.New(gvk)
+			if err != nil {
+				return true, nil, apierrors.NewBadRequest(err.Error())
+			}
+			if err := json.Unmarshal(patch, obj); err != nil {
+				return true, nil, apierrors.NewBadRequest(err.Error())
+			}
+			if err := setNamespace(obj, ns); err != nil {
+				return true, nil, err
+			}
+			if err := tracker.Create(gvr, obj, ns); err != nil {
+				return true, nil, err
+			}
+			return true, obj, nil
+		}
+		if err != nil {
+			return true, nil, err
+		}
+
+		original, err := json.Marshal(existing)
+		if err != nil {
+			return true, nil, err
+		}
+		merged, err := jsonpatch.MergePatch(original, patch)
+		if err != nil {
+			return true, nil, apierrors.NewBadRequest(err.Error())
+		}
+		obj, err := scheme.New(gvk)
+		if err != nil {
+			return true, nil, apierrors.NewBadRequest(err.Error())
+		}
+		if err := json.Unmarshal(merged, obj); err != nil {
+			return true, nil, apierrors.NewBadRequest(err.Error())
+		}
+		if err := tracker.Update(gvr, obj, ns); err != nil {
+			return true, nil, err
+		}
+		return true, obj, nil
+	}
+}
+
+// applyPatch returns the part of the apply configuration the call may change,
+// i.e. the status for the status subresource and everything else otherwise,
+// together with the kind of the configured object.
+func applyPatch(data []byte, status bool) ([]byte, schema.GroupVersionKind, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, schema.GroupVersionKind{}, err
+	}
+	apiVersion, _ := fields["apiVersion"].(string)
+	kind, _ := fields["kind"].(string)
+	if apiVersion == "" || kind == "" {
+		return nil, schema.GroupVersionKind{}, fmt.Errorf("apiVersion and kind must be set in apply configurations")
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, schema.GroupVersionKind{}, err
+	}
+
+	if status {
+		statusFields, ok := fields["status"]
+		fields = map[string]interface{}{}
+		if ok {
+			fields["status"] = statusFields
+		}
+	} else {
+		delete(fields, "status")
+	}
+	patch, err := json.Marshal(fields)
+	return patch, gv.WithKind(kind), err
+}
+
+func setNamespace(obj runtime.Object, ns string) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	if accessor.GetNamespace() == "" {
+		accessor.SetNamespace(ns)
+	}
+	return nil
+}
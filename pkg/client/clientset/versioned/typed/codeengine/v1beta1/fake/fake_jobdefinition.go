@@ -20,8 +20,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	codeenginev1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/applyconfiguration/codeengine/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -140,3 +143,48 @@ func (c *FakeJobDefinitions) Patch(ctx context.Context, name string, pt types.Pa
 	}
 	return obj.(*v1beta1.JobDefinition), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied jobDefinition.
+func (c *FakeJobDefinitions) Apply(ctx context.Context, jobDefinition *codeenginev1beta1.JobDefinitionApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobDefinition, err error) {
+	if jobDefinition == nil {
+		return nil, fmt.Errorf("jobDefinition provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(jobDefinition)
+	if err != nil {
+		return nil, err
+	}
+	name := jobDefinition.Name
+	if name == nil {
+		return nil, fmt.Errorf("jobDefinition.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(jobdefinitionsResource, c.ns, *name, types.ApplyPatchType, data), &v1beta1.JobDefinition{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.JobDefinition), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeJobDefinitions) ApplyStatus(ctx context.Context, jobDefinition *codeenginev1beta1.JobDefinitionApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobDefinition, err error) {
+	if jobDefinition == nil {
+		return nil, fmt.Errorf("jobDefinition provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(jobDefinition)
+	if err != nil {
+		return nil, err
+	}
+	name := jobDefinition.Name
+	if name == nil {
+		return nil, fmt.Errorf("jobDefinition.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(jobdefinitionsResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1beta1.JobDefinition{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.JobDefinition), err
+}
@@ -20,8 +20,11 @@ package fake
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 
 	v1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	codeenginev1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/applyconfiguration/codeengine/v1beta1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	labels "k8s.io/apimachinery/pkg/labels"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -140,3 +143,48 @@ func (c *FakeJobRuns) Patch(ctx context.Context, name string, pt types.PatchType
 	}
 	return obj.(*v1beta1.JobRun), err
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied jobRun.
+func (c *FakeJobRuns) Apply(ctx context.Context, jobRun *codeenginev1beta1.JobRunApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobRun, err error) {
+	if jobRun == nil {
+		return nil, fmt.Errorf("jobRun provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(jobRun)
+	if err != nil {
+		return nil, err
+	}
+	name := jobRun.Name
+	if name == nil {
+		return nil, fmt.Errorf("jobRun.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(jobrunsResource, c.ns, *name, types.ApplyPatchType, data), &v1beta1.JobRun{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.JobRun), err
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *FakeJobRuns) ApplyStatus(ctx context.Context, jobRun *codeenginev1beta1.JobRunApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobRun, err error) {
+	if jobRun == nil {
+		return nil, fmt.Errorf("jobRun provided to Apply must not be nil")
+	}
+	data, err := json.Marshal(jobRun)
+	if err != nil {
+		return nil, err
+	}
+	name := jobRun.Name
+	if name == nil {
+		return nil, fmt.Errorf("jobRun.Name must be provided to Apply")
+	}
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(jobrunsResource, c.ns, *name, types.ApplyPatchType, data, "status"), &v1beta1.JobRun{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1beta1.JobRun), err
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+
+	v1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	codeenginev1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/typed/codeengine/v1beta1"
+)
+
+// WaitForCompletion blocks until the named jobRun is finished or ctx is done.
+func (c *FakeJobRuns) WaitForCompletion(ctx context.Context, name string, opts codeenginev1beta1.WaitOptions) (*codeenginev1beta1.WaitResult, error) {
+	return codeenginev1beta1.WaitForJobRunCompletion(ctx, c, name, opts)
+}
+
+// RerunFailed creates a new jobRun that repeats exactly the failed indices of the named jobRun.
+func (c *FakeJobRuns) RerunFailed(ctx context.Context, name string, opts codeenginev1beta1.RerunOptions) (*v1beta1.JobRun, error) {
+	return codeenginev1beta1.RerunFailedJobRun(ctx, c, name, opts)
+}
@@ -20,9 +20,12 @@ package v1beta1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	codeenginev1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/applyconfiguration/codeengine/v1beta1"
 	scheme "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -47,6 +50,8 @@ type JobDefinitionInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.JobDefinitionList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.JobDefinition, err error)
+	Apply(ctx context.Context, jobDefinition *codeenginev1beta1.JobDefinitionApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobDefinition, err error)
+	ApplyStatus(ctx context.Context, jobDefinition *codeenginev1beta1.JobDefinitionApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobDefinition, err error)
 	JobDefinitionExpansion
 }
 
@@ -193,3 +198,59 @@ func (c *jobDefinitions) Patch(ctx context.Context, name string, pt types.PatchT
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied jobDefinition.
+func (c *jobDefinitions) Apply(ctx context.Context, jobDefinition *codeenginev1beta1.JobDefinitionApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobDefinition, err error) {
+	if jobDefinition == nil {
+		return nil, fmt.Errorf("jobDefinition provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(jobDefinition)
+	if err != nil {
+		return nil, err
+	}
+	name := jobDefinition.Name
+	if name == nil {
+		return nil, fmt.Errorf("jobDefinition.Name must be provided to Apply")
+	}
+	result = &v1beta1.JobDefinition{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("jobdefinitions").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *jobDefinitions) ApplyStatus(ctx context.Context, jobDefinition *codeenginev1beta1.JobDefinitionApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobDefinition, err error) {
+	if jobDefinition == nil {
+		return nil, fmt.Errorf("jobDefinition provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(jobDefinition)
+	if err != nil {
+		return nil, err
+	}
+
+	name := jobDefinition.Name
+	if name == nil {
+		return nil, fmt.Errorf("jobDefinition.Name must be provided to Apply")
+	}
+
+	result = &v1beta1.JobDefinition{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("jobdefinitions").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -20,9 +20,12 @@ package v1beta1
 
 import (
 	"context"
+	json "encoding/json"
+	"fmt"
 	"time"
 
 	v1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	codeenginev1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/applyconfiguration/codeengine/v1beta1"
 	scheme "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/scheme"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
@@ -47,6 +50,8 @@ type JobRunInterface interface {
 	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.JobRunList, error)
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1beta1.JobRun, err error)
+	Apply(ctx context.Context, jobRun *codeenginev1beta1.JobRunApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobRun, err error)
+	ApplyStatus(ctx context.Context, jobRun *codeenginev1beta1.JobRunApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobRun, err error)
 	JobRunExpansion
 }
 
@@ -193,3 +198,59 @@ func (c *jobRuns) Patch(ctx context.Context, name string, pt types.PatchType, da
 		Into(result)
 	return
 }
+
+// Apply takes the given apply declarative configuration, applies it and returns the applied jobRun.
+func (c *jobRuns) Apply(ctx context.Context, jobRun *codeenginev1beta1.JobRunApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobRun, err error) {
+	if jobRun == nil {
+		return nil, fmt.Errorf("jobRun provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(jobRun)
+	if err != nil {
+		return nil, err
+	}
+	name := jobRun.Name
+	if name == nil {
+		return nil, fmt.Errorf("jobRun.Name must be provided to Apply")
+	}
+	result = &v1beta1.JobRun{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("jobruns").
+		Name(*name).
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// ApplyStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
+func (c *jobRuns) ApplyStatus(ctx context.Context, jobRun *codeenginev1beta1.JobRunApplyConfiguration, opts v1.ApplyOptions) (result *v1beta1.JobRun, err error) {
+	if jobRun == nil {
+		return nil, fmt.Errorf("jobRun provided to Apply must not be nil")
+	}
+	patchOpts := opts.ToPatchOptions()
+	data, err := json.Marshal(jobRun)
+	if err != nil {
+		return nil, err
+	}
+
+	name := jobRun.Name
+	if name == nil {
+		return nil, fmt.Errorf("jobRun.Name must be provided to Apply")
+	}
+
+	result = &v1beta1.JobRun{}
+	err = c.client.Patch(types.ApplyPatchType).
+		Namespace(c.ns).
+		Resource("jobruns").
+		Name(*name).
+		SubResource("status").
+		VersionedParams(&patchOpts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
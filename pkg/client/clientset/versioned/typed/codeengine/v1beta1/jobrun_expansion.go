@@ -0,0 +1,388 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+
+	v1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+// DefaultWaitPollInterval is the interval used by WaitForCompletion
+// to poll the jobRun while its watch can't be established.
+const DefaultWaitPollInterval = 5 * time.Second
+
+// minRewatchDelay is the delay before WaitForCompletion re-establishes a watch which ended
+// without any event. It doubles for every such watch in a row, up to the poll interval.
+const minRewatchDelay = 100 * time.Millisecond
+
+// JobRunExpansion has additional methods to work with JobRun resources.
+type JobRunExpansion interface {
+	// WaitForCompletion blocks until the named jobRun is finished or ctx is done.
+	WaitForCompletion(ctx context.Context, name string, opts WaitOptions) (*WaitResult, error)
+	// RerunFailed creates a new jobRun that repeats exactly the failed indices of the named jobRun.
+	RerunFailed(ctx context.Context, name string, opts RerunOptions) (*v1beta1.JobRun, error)
+}
+
+// WaitOptions configures WaitForCompletion.
+type WaitOptions struct {
+	// Timeout limits the time to wait in addition to the deadline of the context.
+	// No additional limit is applied if not set.
+	Timeout time.Duration
+
+	// PollInterval is the interval to poll the jobRun at while its watch fails.
+	// DefaultWaitPollInterval is used if not set.
+	PollInterval time.Duration
+
+	// OnProgress is called with every observed version of the jobRun whose status changed,
+	// e.g. to report the Pending, Running, Succeeded and Failed counters.
+	OnProgress func(jobRun *v1beta1.JobRun)
+}
+
+// WaitOutcome is the outcome of waiting for a jobRun.
+type WaitOutcome string
+
+const (
+	// WaitOutcomeComplete means the jobRun completed its execution.
+	WaitOutcomeComplete WaitOutcome = "Complete"
+	// WaitOutcomeFailed means the jobRun failed its execution.
+	WaitOutcomeFailed WaitOutcome = "Failed"
+	// WaitOutcomeTimeout means the jobRun did not finish before the deadline.
+	WaitOutcomeTimeout WaitOutcome = "Timeout"
+)
+
+// WaitResult is the result of WaitForCompletion.
+type WaitResult struct {
+	// Outcome tells whether the jobRun completed, failed or the wait timed out.
+	Outcome WaitOutcome
+
+	// JobRun is the latest observed version of the jobRun,
+	// nil if the wait timed out before the jobRun could be read.
+	JobRun *v1beta1.JobRun
+
+	// FailedIndices holds the failed indices of a failed jobRun.
+	FailedIndices v1beta1.IndexSet
+}
+
+// WaitForCompletion blocks until the named jobRun is finished or ctx is done.
+func (c *jobRuns) WaitForCompletion(ctx context.Context, name string, opts WaitOptions) (*WaitResult, error) {
+	return WaitForJobRunCompletion(ctx, c, name, opts)
+}
+
+// WaitForJobRunCompletion implements JobRunExpansion.WaitForCompletion on top of any JobRunInterface.
+// The jobRun is watched starting at the resource version of the latest observed object,
+// or read again once the watch is established if that version is unknown.
+// While the watch can't be established the jobRun is polled instead, and a watch which
+// ends without any event is re-established with an increasing delay.
+// Reaching the deadline of ctx or opts.Timeout yields a result with WaitOutcomeTimeout,
+// cancellation of ctx is returned as error.
+func WaitForJobRunCompletion(ctx context.Context, c JobRunInterface, name string, opts WaitOptions) (*WaitResult, error) {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultWaitPollInterval
+	}
+
+	w := &jobRunWaiter{client: c, name: name, opts: opts}
+	for {
+		if w.latest == nil || w.resourceVersion == "" {
+			if err := w.get(ctx); err != nil {
+				return w.done(ctx, err)
+			}
+		}
+		if w.latest.IsJobRunFinished() {
+			return w.result()
+		}
+
+		events := w.events
+		err := w.watch(ctx)
+		if w.latest.IsJobRunFinished() {
+			return w.result()
+		}
+		switch {
+		case err != nil:
+			if ctx.Err() != nil || apierrors.IsNotFound(err) {
+				return w.done(ctx, err)
+			}
+			// Fall back to polling until the watch can be established again.
+			if err := w.sleep(ctx, w.opts.PollInterval); err != nil {
+				return w.done(ctx, err)
+			}
+			w.resourceVersion = ""
+		case w.events == events:
+			// Don't spin if the server keeps closing the watch right away.
+			if err := w.sleep(ctx, w.nextRewatchDelay()); err != nil {
+				return w.done(ctx, err)
+			}
+		default:
+			w.rewatchDelay = 0
+		}
+	}
+}
+
+type jobRunWaiter struct {
+	client          JobRunInterface
+	name            string
+	opts            WaitOptions
+	latest          *v1beta1.JobRun
+	resourceVersion string
+	// events counts the watch events received.
+	events int
+	// rewatchDelay is the latest delay before re-establishing a watch which ended without any event.
+	rewatchDelay time.Duration
+}
+
+func (w *jobRunWaiter) get(ctx context.Context) error {
+	jr, err := w.client.Get(ctx, w.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	w.observe(jr)
+	return nil
+}
+
+// watch consumes watch events until the jobRun is finished or the watch ends.
+// A nil error means the watch was closed by the server and can be resumed.
+func (w *jobRunWaiter) watch(ctx context.Context) error {
+	resourceVersion := w.resourceVersion
+	watcher, err := w.client.Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", w.name).String(),
+		ResourceVersion: resourceVersion,
+	})
+	if err != nil {
+		return err
+	}
+	defer watcher.Stop()
+
+	if resourceVersion == "" {
+		// The watch starts at an unknown version, don't miss the changes
+		// between the latest observed version and the start of the watch.
+		if err := w.get(ctx); err != nil {
+			return err
+		}
+		if w.latest.IsJobRunFinished() {
+			return nil
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return nil
+			}
+			w.events++
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				jr, ok := event.Object.(*v1beta1.JobRun)
+				if !ok || jr.Name != w.name {
+					continue
+				}
+				w.observe(jr)
+				if jr.IsJobRunFinished() {
+					return nil
+				}
+			case watch.Deleted:
+				if jr, ok := event.Object.(*v1beta1.JobRun); ok && jr.Name == w.name {
+					return apierrors.NewNotFound(v1beta1.Resource("jobruns"), w.name)
+				}
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				var status apierrors.APIStatus
+				if errors.As(err, &status) && status.Status().Code == http.StatusGone {
+					// The resource version is too old, start over with a fresh one.
+					w.resourceVersion = ""
+					return nil
+				}
+				return err
+			}
+		}
+	}
+}
+
+func (w *jobRunWaiter) observe(jr *v1beta1.JobRun) {
+	changed := w.latest == nil || !equalStatus(&w.latest.Status, &jr.Status)
+	w.latest = jr
+	w.resourceVersion = jr.ResourceVersion
+	if changed && w.opts.OnProgress != nil {
+		w.opts.OnProgress(jr.DeepCopy())
+	}
+}
+
+// nextRewatchDelay returns the delay before re-establishing a watch which ended without any event.
+func (w *jobRunWaiter) nextRewatchDelay() time.Duration {
+	w.rewatchDelay *= 2
+	if w.rewatchDelay < minRewatchDelay {
+		w.rewatchDelay = minRewatchDelay
+	}
+	if w.rewatchDelay > w.opts.PollInterval {
+		w.rewatchDelay = w.opts.PollInterval
+	}
+	return w.rewatchDelay
+}
+
+func (w *jobRunWaiter) sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (w *jobRunWaiter) result() (*WaitResult, error) {
+	res := &WaitResult{Outcome: WaitOutcomeComplete, JobRun: w.latest}
+	if w.latest.CheckCondition(v1beta1.JobFailed) {
+		res.Outcome = WaitOutcomeFailed
+		failed, err := w.latest.GetFailedIndexSet()
+		if err != nil {
+			return res, err
+		}
+		res.FailedIndices = failed
+	}
+	return res, nil
+}
+
+// done returns the timeout result if ctx reached its deadline, err otherwise.
+func (w *jobRunWaiter) done(ctx context.Context, err error) (*WaitResult, error) {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &WaitResult{Outcome: WaitOutcomeTimeout, JobRun: w.latest}, nil
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("waiting for jobRun %q: %w", w.name, ctx.Err())
+	}
+	return nil, err
+}
+
+// equalStatus tells whether the statuses report the same progress: counters, index lists and conditions,
+// ignoring timestamps and messages.
+func equalStatus(a, b *v1beta1.JobRunStatus) bool {
+	if a.Pending != b.Pending || a.Running != b.Running || a.Succeeded != b.Succeeded ||
+		a.Failed != b.Failed || a.Unknown != b.Unknown || a.Requested != b.Requested || a.Throttled != b.Throttled {
+		return false
+	}
+	if stringValue(a.SucceededIndices) != stringValue(b.SucceededIndices) || stringValue(a.FailedIndices) != stringValue(b.FailedIndices) {
+		return false
+	}
+	if len(a.Conditions) != len(b.Conditions) {
+		return false
+	}
+	for _, ac := range a.Conditions {
+		found := false
+		for _, bc := range b.Conditions {
+			if ac.Type == bc.Type {
+				found = ac.Status == bc.Status && ac.Reason == bc.Reason
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// RerunOptions configures RerunFailed.
+type RerunOptions struct {
+	// Name of the new jobRun. The name is generated from the original one if not set.
+	Name string
+
+	// RetryLimit overrides the retryLimit of the original jobRun, if set.
+	RetryLimit *int64
+
+	// Resources overrides the resources of the main container of the original jobRun, if set.
+	// Sidecars and init containers keep their resources.
+	// The main container is the one named by mainContainer or else the first one.
+	// A jobRun which inherits its containers from its jobDefinition gets a container
+	// which overrides the main container of the jobDefinition (see v1beta1.MergeJobDefinitionSpec).
+	Resources *corev1.ResourceRequirements
+
+	// CreateOptions are passed on to create the new jobRun.
+	CreateOptions metav1.CreateOptions
+}
+
+// RerunFailed creates a new jobRun that repeats exactly the failed indices of the named jobRun.
+func (c *jobRuns) RerunFailed(ctx context.Context, name string, opts RerunOptions) (*v1beta1.JobRun, error) {
+	return RerunFailedJobRun(ctx, c, name, opts)
+}
+
+// RerunFailedJobRun implements JobRunExpansion.RerunFailed on top of any JobRunInterface.
+// The new jobRun is built by JobRun.NewRerunOfFailedIndices and customized by opts.
+func RerunFailedJobRun(ctx context.Context, c JobRunInterface, name string, opts RerunOptions) (*v1beta1.JobRun, error) {
+	jr, err := c.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if !jr.IsJobRunFinished() {
+		return nil, fmt.Errorf("jobRun %q is not finished yet", name)
+	}
+
+	rerun, err := jr.NewRerunOfFailedIndices()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Name != "" {
+		rerun.Name = opts.Name
+		rerun.GenerateName = ""
+	}
+	if opts.RetryLimit != nil {
+		retryLimit := *opts.RetryLimit
+		rerun.Spec.JobDefinitionSpec.RetryLimit = &retryLimit
+	}
+	if opts.Resources != nil {
+		overrideMainResources(&rerun.Spec.JobDefinitionSpec.Template, opts.Resources)
+	}
+
+	return c.Create(ctx, rerun, opts.CreateOptions)
+}
+
+// overrideMainResources sets the resources of the main container of the template.
+// The main container is added if the template doesn't have it, so that it's merged
+// with the main container of the jobDefinition: by name if mainContainer is set,
+// otherwise as the single unnamed container of the template.
+func overrideMainResources(t *v1beta1.JobPodTemplate, resources *corev1.ResourceRequirements) {
+	main := t.GetMainContainer()
+	if main == nil {
+		t.Containers = append(t.Containers, corev1.Container{Name: t.MainContainer})
+		main = &t.Containers[len(t.Containers)-1]
+	}
+	main.Resources = *resources.DeepCopy()
+}
@@ -0,0 +1,317 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/clock"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/fake"
+	typed "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/typed/codeengine/v1beta1"
+)
+
+// waitHarness serves the watches of WaitForJobRunCompletion from a script.
+type waitHarness struct {
+	client *fake.Clientset
+
+	mu sync.Mutex
+	// watches holds the result of each watch call in turn, the last one is repeated.
+	watches []func() (watch.Interface, error)
+	calls   int
+}
+
+func newWaitHarness(t *testing.T, jr *v1beta1.JobRun) *waitHarness {
+	h := &waitHarness{client: fake.NewSimpleClientset(jr)}
+	h.client.PrependWatchReactor("jobruns", func(clienttesting.Action) (bool, watch.Interface, error) {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if len(h.watches) == 0 {
+			t.Fatal("unexpected watch")
+		}
+		next := h.watches[0]
+		if len(h.watches) > 1 {
+			h.watches = h.watches[1:]
+		}
+		h.calls++
+		w, err := next()
+		return true, w, err
+	})
+	return h
+}
+
+// events returns a watch which delivers the events and stays open.
+func events(events ...watch.Event) func() (watch.Interface, error) {
+	return func() (watch.Interface, error) {
+		w := watch.NewFakeWithChanSize(len(events), false)
+		for _, e := range events {
+			w.Action(e.Type, e.Object)
+		}
+		return w, nil
+	}
+}
+
+// closed returns a watch which the server closes right away.
+func closed() (watch.Interface, error) {
+	w := watch.NewFake()
+	w.Stop()
+	return w, nil
+}
+
+func (h *waitHarness) wait(opts typed.WaitOptions) (*typed.WaitResult, error) {
+	return typed.WaitForJobRunCompletion(context.Background(), h.client.CodeengineV1beta1().JobRuns("ns"), "run", opts)
+}
+
+func (h *waitHarness) watchCalls() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.calls
+}
+
+func jobRun(mark func(m *v1beta1.JobRunConditionManager)) *v1beta1.JobRun {
+	jr := &v1beta1.JobRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}
+	m := jr.Status.ManageConditions(clock.RealClock{})
+	m.MarkPending()
+	if mark != nil {
+		mark(m)
+	}
+	return jr
+}
+
+func running(m *v1beta1.JobRunConditionManager)  { m.MarkRunning() }
+func complete(m *v1beta1.JobRunConditionManager) { m.MarkComplete() }
+
+func failed(jr *v1beta1.JobRun) *v1beta1.JobRun {
+	jr.Status.ManageConditions(clock.RealClock{}).MarkFailed("IndexFailed", "index 3 failed")
+	indices := "1,3"
+	jr.Status.FailedIndices = &indices
+	return jr
+}
+
+func TestWaitForCompletionFinishedAlready(t *testing.T) {
+	h := newWaitHarness(t, jobRun(complete))
+	res, err := h.wait(typed.WaitOptions{})
+	if err != nil {
+		t.Fatalf("WaitForJobRunCompletion: %v", err)
+	}
+	if res.Outcome != typed.WaitOutcomeComplete || h.watchCalls() != 0 {
+		t.Errorf("outcome %s after %d watches, want Complete without watching", res.Outcome, h.watchCalls())
+	}
+}
+
+func TestWaitForCompletionComplete(t *testing.T) {
+	h := newWaitHarness(t, jobRun(nil))
+	h.watches = append(h.watches, events(
+		watch.Event{Type: watch.Modified, Object: jobRun(running)},
+		// Same status, no progress.
+		watch.Event{Type: watch.Modified, Object: jobRun(running)},
+		watch.Event{Type: watch.Modified, Object: jobRun(complete)},
+	))
+
+	var progress []string
+	res, err := h.wait(typed.WaitOptions{OnProgress: func(jr *v1beta1.JobRun) {
+		progress = append(progress, string(jr.Phase()))
+	}})
+	if err != nil {
+		t.Fatalf("WaitForJobRunCompletion: %v", err)
+	}
+	if res.Outcome != typed.WaitOutcomeComplete || !res.JobRun.CheckCondition(v1beta1.JobComplete) {
+		t.Errorf("outcome %s, want Complete", res.Outcome)
+	}
+	if want := []string{"Pending", "Running", "Complete"}; len(progress) != len(want) || strings.Join(progress, ",") != strings.Join(want, ",") {
+		t.Errorf("progress %v, want %v", progress, want)
+	}
+}
+
+func TestWaitForCompletionFailed(t *testing.T) {
+	h := newWaitHarness(t, jobRun(running))
+	h.watches = append(h.watches, events(watch.Event{Type: watch.Modified, Object: failed(jobRun(running))}))
+
+	res, err := h.wait(typed.WaitOptions{})
+	if err != nil {
+		t.Fatalf("WaitForJobRunCompletion: %v", err)
+	}
+	if res.Outcome != typed.WaitOutcomeFailed {
+		t.Fatalf("outcome %s, want Failed", res.Outcome)
+	}
+	if got := res.FailedIndices.String(); got != "1,3" {
+		t.Errorf("failed indices %q, want 1,3", got)
+	}
+}
+
+func TestWaitForCompletionTimeout(t *testing.T) {
+	h := newWaitHarness(t, jobRun(running))
+	h.watches = append(h.watches, events())
+
+	res, err := h.wait(typed.WaitOptions{Timeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("WaitForJobRunCompletion: %v", err)
+	}
+	if res.Outcome != typed.WaitOutcomeTimeout {
+		t.Fatalf("outcome %s, want Timeout", res.Outcome)
+	}
+	if res.JobRun == nil || !res.JobRun.CheckCondition(v1beta1.JobRunning) {
+		t.Errorf("the result holds %v, want the running jobRun", res.JobRun)
+	}
+}
+
+func TestWaitForCompletionResumesAfterGone(t *testing.T) {
+	h := newWaitHarness(t, jobRun(running))
+	gone := apierrors.NewResourceExpired("too old resource version")
+	h.watches = append(h.watches,
+		events(watch.Event{Type: watch.Error, Object: &gone.ErrStatus}),
+		events(watch.Event{Type: watch.Modified, Object: jobRun(complete)}),
+	)
+
+	res, err := h.wait(typed.WaitOptions{})
+	if err != nil {
+		t.Fatalf("WaitForJobRunCompletion: %v", err)
+	}
+	if res.Outcome != typed.WaitOutcomeComplete || h.watchCalls() != 2 {
+		t.Errorf("outcome %s after %d watches, want Complete after 2", res.Outcome, h.watchCalls())
+	}
+}
+
+func TestWaitForCompletionPollsWhileWatchFails(t *testing.T) {
+	h := newWaitHarness(t, jobRun(running))
+	h.watches = append(h.watches, func() (watch.Interface, error) {
+		return nil, errors.New("watch unavailable")
+	})
+	// The jobRun completes while it can't be watched.
+	gets := 0
+	h.client.PrependReactor("get", "jobruns", func(clienttesting.Action) (bool, runtime.Object, error) {
+		gets++
+		if gets > 1 {
+			return true, jobRun(complete), nil
+		}
+		return false, nil, nil
+	})
+
+	res, err := h.wait(typed.WaitOptions{PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("WaitForJobRunCompletion: %v", err)
+	}
+	if res.Outcome != typed.WaitOutcomeComplete || gets != 2 {
+		t.Errorf("outcome %s after %d gets, want Complete after 2", res.Outcome, gets)
+	}
+}
+
+func TestWaitForCompletionDeleted(t *testing.T) {
+	h := newWaitHarness(t, jobRun(running))
+	h.watches = append(h.watches, events(watch.Event{Type: watch.Deleted, Object: jobRun(running)}))
+
+	_, err := h.wait(typed.WaitOptions{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("WaitForJobRunCompletion returned %v, want NotFound", err)
+	}
+}
+
+func TestWaitForCompletionBacksOffWhenWatchCloses(t *testing.T) {
+	h := newWaitHarness(t, jobRun(running))
+	h.watches = append(h.watches, closed, closed, closed, events(watch.Event{Type: watch.Modified, Object: jobRun(complete)}))
+
+	start := time.Now()
+	res, err := h.wait(typed.WaitOptions{PollInterval: time.Second})
+	if err != nil {
+		t.Fatalf("WaitForJobRunCompletion: %v", err)
+	}
+	if res.Outcome != typed.WaitOutcomeComplete || h.watchCalls() != 4 {
+		t.Fatalf("outcome %s after %d watches, want Complete after 4", res.Outcome, h.watchCalls())
+	}
+	// 100ms, 200ms and 400ms before each of the watches after the closed ones.
+	if elapsed := time.Since(start); elapsed < 700*time.Millisecond {
+		t.Errorf("re-established the closed watches within %s, want a backoff", elapsed)
+	}
+}
+
+func TestRerunFailedOverridesMainResources(t *testing.T) {
+	resources := &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+	}
+	sidecarResources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+	}
+	definition := &v1beta1.JobDefinitionSpec{Template: v1beta1.JobPodTemplate{
+		InitContainers: []corev1.Container{{Name: "init", Image: "init", Resources: sidecarResources}},
+		Containers: []corev1.Container{
+			{Name: "proxy", Image: "proxy", Resources: sidecarResources},
+			{Name: "main", Image: "main"},
+		},
+		MainContainer: "main",
+	}}
+
+	tests := []struct {
+		name string
+		spec v1beta1.JobRunSpec
+	}{{
+		name: "complete spec",
+		spec: v1beta1.JobRunSpec{JobDefinitionSpec: *definition.DeepCopy()},
+	}, {
+		name: "inherited containers",
+		spec: v1beta1.JobRunSpec{JobDefinitionRef: "def"},
+	}, {
+		name: "inherited main container",
+		spec: v1beta1.JobRunSpec{JobDefinitionRef: "def", JobDefinitionSpec: v1beta1.JobDefinitionSpec{
+			Template: v1beta1.JobPodTemplate{
+				Containers:    []corev1.Container{{Name: "proxy", Image: "proxy:2"}},
+				MainContainer: "main",
+			},
+		}},
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jr := failed(jobRun(running))
+			jr.Spec = test.spec
+			client := fake.NewSimpleClientset(jr)
+
+			rerun, err := typed.RerunFailedJobRun(context.Background(), client.CodeengineV1beta1().JobRuns("ns"), "run",
+				typed.RerunOptions{Resources: resources})
+			if err != nil {
+				t.Fatalf("RerunFailedJobRun: %v", err)
+			}
+
+			spec := rerun.Spec.JobDefinitionSpec
+			if rerun.Spec.JobDefinitionRef != "" {
+				spec = v1beta1.MergeJobDefinitionSpec(definition, &spec)
+			}
+			want := map[string]corev1.ResourceRequirements{"init": sidecarResources, "proxy": sidecarResources, "main": *resources}
+			for _, c := range append(spec.Template.InitContainers, spec.Template.Containers...) {
+				if got, ok := want[c.Name]; !ok || !equality.Semantic.DeepEqual(c.Resources, got) {
+					t.Errorf("container %q has resources %v, want %v", c.Name, c.Resources, got)
+				}
+				delete(want, c.Name)
+			}
+			if len(want) != 0 {
+				t.Errorf("missing containers %v in %v", want, spec.Template)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	context "context"
+	fmt "fmt"
+
+	v1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	codeenginev1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/applyconfiguration/codeengine/v1beta1"
+	typedcodeenginev1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/typed/codeengine/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	dynamic "k8s.io/client-go/dynamic"
+)
+
+// The dynamic client wrapper is generated with Apply and ApplyStatus for Kubernetes types only,
+// the ones below complete the typed interfaces of the codeengine resources.
+
+func (w *wrapCodeengineV1beta1JobDefinitionImpl) Apply(ctx context.Context, in *codeenginev1beta1.JobDefinitionApplyConfiguration, opts v1.ApplyOptions) (*v1beta1.JobDefinition, error) {
+	if in == nil {
+		return nil, fmt.Errorf("jobDefinition provided to Apply must not be nil")
+	}
+	in.WithKind("JobDefinition").WithAPIVersion("codeengine.cloud.ibm.com/v1beta1")
+	out := &v1beta1.JobDefinition{}
+	if err := apply(ctx, w.dyn.Namespace(w.namespace), in, in.Name, opts, false, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapCodeengineV1beta1JobDefinitionImpl) ApplyStatus(ctx context.Context, in *codeenginev1beta1.JobDefinitionApplyConfiguration, opts v1.ApplyOptions) (*v1beta1.JobDefinition, error) {
+	if in == nil {
+		return nil, fmt.Errorf("jobDefinition provided to Apply must not be nil")
+	}
+	in.WithKind("JobDefinition").WithAPIVersion("codeengine.cloud.ibm.com/v1beta1")
+	out := &v1beta1.JobDefinition{}
+	if err := apply(ctx, w.dyn.Namespace(w.namespace), in, in.Name, opts, true, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapCodeengineV1beta1JobRunImpl) Apply(ctx context.Context, in *codeenginev1beta1.JobRunApplyConfiguration, opts v1.ApplyOptions) (*v1beta1.JobRun, error) {
+	if in == nil {
+		return nil, fmt.Errorf("jobRun provided to Apply must not be nil")
+	}
+	in.WithKind("JobRun").WithAPIVersion("codeengine.cloud.ibm.com/v1beta1")
+	out := &v1beta1.JobRun{}
+	if err := apply(ctx, w.dyn.Namespace(w.namespace), in, in.Name, opts, false, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (w *wrapCodeengineV1beta1JobRunImpl) ApplyStatus(ctx context.Context, in *codeenginev1beta1.JobRunApplyConfiguration, opts v1.ApplyOptions) (*v1beta1.JobRun, error) {
+	if in == nil {
+		return nil, fmt.Errorf("jobRun provided to Apply must not be nil")
+	}
+	in.WithKind("JobRun").WithAPIVersion("codeengine.cloud.ibm.com/v1beta1")
+	out := &v1beta1.JobRun{}
+	if err := apply(ctx, w.dyn.Namespace(w.namespace), in, in.Name, opts, true, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// apply sends the apply configuration in to the resource, or to its status subresource,
+// and converts the result into out.
+func apply(ctx context.Context, dyn dynamic.ResourceInterface, in interface{}, name *string, opts v1.ApplyOptions, status bool, out runtime.Object) error {
+	if name == nil {
+		return fmt.Errorf("name must be provided to Apply")
+	}
+	uo := &unstructured.Unstructured{}
+	if err := convert(in, uo); err != nil {
+		return err
+	}
+	var err error
+	if status {
+		uo, err = dyn.ApplyStatus(ctx, *name, uo, opts)
+	} else {
+		uo, err = dyn.Apply(ctx, *name, uo, opts)
+	}
+	if err != nil {
+		return err
+	}
+	return convert(uo, out)
+}
+
+// WaitForCompletion blocks until the named jobRun is finished or ctx is done.
+// Watch is not supported by the dynamic client wrapper, so the jobRun is polled.
+func (w *wrapCodeengineV1beta1JobRunImpl) WaitForCompletion(ctx context.Context, name string, opts typedcodeenginev1beta1.WaitOptions) (*typedcodeenginev1beta1.WaitResult, error) {
+	return typedcodeenginev1beta1.WaitForJobRunCompletion(ctx, w, name, opts)
+}
+
+// RerunFailed creates a new jobRun that repeats exactly the failed indices of the named jobRun.
+func (w *wrapCodeengineV1beta1JobRunImpl) RerunFailed(ctx context.Context, name string, opts typedcodeenginev1beta1.RerunOptions) (*v1beta1.JobRun, error) {
+	return typedcodeenginev1beta1.RerunFailedJobRun(ctx, w, name, opts)
+}
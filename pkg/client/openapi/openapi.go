@@ -0,0 +1,64 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package openapi holds the OpenAPI definitions of the codeengine API types
+// and of the Kubernetes types they refer to.
+package openapi
+
+import (
+	common "k8s.io/kube-openapi/pkg/common"
+	spec "k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// GetDefinitions returns the definitions generated by openapi-gen, completed with
+// the knative types which don't carry openapi-gen markers.
+func GetDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
+	definitions := GetOpenAPIDefinitions(ref)
+	for name, definition := range knativeDefinitions(ref) {
+		definitions[name] = definition
+	}
+	return definitions
+}
+
+func knativeDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
+	return map[string]common.OpenAPIDefinition{
+		"knative.dev/pkg/apis.URL": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "URL is an absolute or relative URL.",
+					Type:        []string{"string"},
+				},
+			},
+		},
+		"knative.dev/pkg/apis/duck/v1.Addressable": {
+			Schema: spec.Schema{
+				SchemaProps: spec.SchemaProps{
+					Description: "Addressable provides a generic mechanism for a custom resource definition to indicate a destination for message delivery.",
+					Type:        []string{"object"},
+					Properties: map[string]spec.Schema{
+						"url": {
+							SchemaProps: spec.SchemaProps{
+								Ref: ref("knative.dev/pkg/apis.URL"),
+							},
+						},
+					},
+				},
+			},
+			Dependencies: []string{
+				"knative.dev/pkg/apis.URL"},
+		},
+	}
+}
@@ -0,0 +1,396 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package simulator simulates the Code Engine job controller, to test JobRun clients
+// against a clientset without a cluster.
+package simulator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+
+	v1beta1 "github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	clientset "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned"
+)
+
+// DefaultAttemptDuration is the duration of an attempt of an index if Rules.Attempt is not set.
+const DefaultAttemptDuration = time.Second
+
+// AttemptResult is the simulated outcome of one attempt to run an index.
+type AttemptResult struct {
+	// Duration is the time the attempt is running.
+	Duration time.Duration
+	// Failed marks the attempt as failed, it succeeds otherwise.
+	Failed bool
+	// ExitCode is the exit code of a failed attempt, 1 if not set.
+	ExitCode int32
+}
+
+// exitCode returns the exit code of the main container at the end of the attempt.
+func (r AttemptResult) exitCode() int32 {
+	switch {
+	case !r.Failed:
+		return 0
+	case r.ExitCode == 0:
+		return 1
+	}
+	return r.ExitCode
+}
+
+// Rules drive the jobRuns of a Simulator.
+type Rules struct {
+	// StartDelay is the time a jobRun stays Pending before its indices start running.
+	StartDelay time.Duration
+
+	// Attempt returns the outcome of an attempt to run the index of the jobRun.
+	// Attempts are counted from 0, failed indices are retried according to the retryLimit and retryPolicy.
+	// Every attempt succeeds after DefaultAttemptDuration if not set.
+	Attempt func(jr *v1beta1.JobRun, idx, attempt int64) AttemptResult
+}
+
+// FailIndices returns an Attempt rule that fails the given indices in their first failedAttempts attempts
+// and succeeds any other attempt. Every attempt takes the given duration.
+func FailIndices(indices v1beta1.IndexSet, failedAttempts int64, duration time.Duration) func(*v1beta1.JobRun, int64, int64) AttemptResult {
+	return func(_ *v1beta1.JobRun, idx, attempt int64) AttemptResult {
+		return AttemptResult{Duration: duration, Failed: indices.Contains(idx) && attempt < failedAttempts}
+	}
+}
+
+// Simulator simulates the Code Engine job controller on top of a clientset,
+// typically the one of pkg/client/clientset/versioned/fake. On each Sync it drives every jobRun through the phases
+// Pending, Running and Complete or Failed according to its rules and the time of its clock,
+// and updates the status counters, succeededIndices, failedIndices, startTime and completionTime.
+// The retryLimit, retryPolicy, parallelism and maxExecutionTime of the jobRuns are respected, indices of jobRuns in daemon mode
+// are restarted whenever they finish, so these jobRuns keep running until they exceed maxExecutionTime.
+// A jobRun is simulated from its startTime, or from the first Sync that sees it if it has none.
+type Simulator struct {
+	client clientset.Interface
+	clock  clock.PassiveClock
+	rules  Rules
+
+	runs map[types.UID]*simulatedRun
+}
+
+type simulatedRun struct {
+	startTime time.Time
+	indices   map[int64]*simulatedIndex
+	// freed holds the finish times of the indices whose slot of the parallelism isn't taken over yet.
+	freed []time.Time
+}
+
+type simulatedIndex struct {
+	startTime    time.Time
+	attempt      int64
+	attemptStart time.Time
+	result       AttemptResult
+	phase        corev1.PodPhase
+}
+
+// New returns a simulator of the jobRuns of client, which uses clk as its time source,
+// e.g. a k8s.io/utils/clock/testing.FakeClock.
+func New(client clientset.Interface, clk clock.PassiveClock, rules Rules) *Simulator {
+	if rules.Attempt == nil {
+		rules.Attempt = func(*v1beta1.JobRun, int64, int64) AttemptResult {
+			return AttemptResult{Duration: DefaultAttemptDuration}
+		}
+	}
+	return &Simulator{
+		client: client,
+		clock:  clk,
+		rules:  rules,
+		runs:   map[types.UID]*simulatedRun{},
+	}
+}
+
+// Sync updates the status of every unfinished jobRun in all namespaces to the current time of the clock.
+func (s *Simulator) Sync(ctx context.Context) error {
+	list, err := s.client.CodeengineV1beta1().JobRuns(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range list.Items {
+		if err := s.syncJobRun(ctx, &list.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Simulator) syncJobRun(ctx context.Context, jr *v1beta1.JobRun) error {
+	if jr.IsJobRunFinished() {
+		return nil
+	}
+
+	jds, err := s.effectiveSpec(ctx, jr)
+	if err != nil {
+		return err
+	}
+	indices, err := jds.GetArrayIndices()
+	if err != nil {
+		return fmt.Errorf("jobRun %s/%s: invalid arraySpec: %w", jr.Namespace, jr.Name, err)
+	}
+
+	now := s.clock.Now()
+	key := s.key(jr)
+	run, ok := s.runs[key]
+	if !ok {
+		run = &simulatedRun{startTime: now, indices: map[int64]*simulatedIndex{}}
+		if jr.Status.StartTime != nil {
+			run.startTime = jr.Status.StartTime.Time
+		}
+		s.runs[key] = run
+	}
+
+	// Simulate on a copy which holds the effective spec, but only update the status.
+	original := jr
+	jr = jr.DeepCopy()
+	jr.Spec.JobDefinitionSpec = *jds
+	if jr.Status.StartTime == nil {
+		jr.Status.StartTime = &metav1.Time{Time: run.startTime}
+	}
+
+	daemon := jr.IsRunningInDaemonMode()
+	deadline := jr.Status.StartTime.Add(time.Duration(*jds.MaxExecutionTime) * time.Second)
+	deadlineExceeded := !now.Before(deadline)
+
+	// Indices held back by the parallelism have no snapshot, as they have no pod yet.
+	snapshots := map[int64]corev1.PodPhase{}
+	start := run.startTime.Add(s.rules.StartDelay)
+	if now.Before(start) {
+		indices.Each(func(idx int64) bool {
+			if jds.Parallelism != nil && int64(len(snapshots)) >= *jds.Parallelism {
+				return false
+			}
+			snapshots[idx] = corev1.PodPending
+			return true
+		})
+	} else {
+		var queued []int64
+		indices.Each(func(idx int64) bool {
+			if _, ok := run.indices[idx]; ok {
+				snapshots[idx] = s.advanceIndex(ctx, jr, run, idx, daemon, now)
+			} else {
+				queued = append(queued, idx)
+			}
+			return true
+		})
+		for _, idx := range queued {
+			at, ok := run.nextSlot(start, jds.Parallelism)
+			if !ok || !at.Before(deadline) {
+				break
+			}
+			run.indices[idx] = &simulatedIndex{startTime: at, attemptStart: at, result: s.rules.Attempt(jr, idx, 0), phase: corev1.PodRunning}
+			snapshots[idx] = s.advanceIndex(ctx, jr, run, idx, daemon, now)
+		}
+	}
+
+	var running, failed, finished int64
+	for idx, phase := range snapshots {
+		if deadlineExceeded && phase != corev1.PodSucceeded && phase != corev1.PodFailed {
+			phase = corev1.PodFailed
+			snapshots[idx] = phase
+		}
+		switch phase {
+		case corev1.PodRunning:
+			running++
+		case corev1.PodFailed:
+			failed++
+			finished++
+		case corev1.PodSucceeded:
+			finished++
+		}
+	}
+
+	s.updateIndexStatuses(jr, run, snapshots, now)
+
+	phases := make([]corev1.PodPhase, 0, len(snapshots))
+	failedSnapshots := map[int64]corev1.PodPhase{}
+	for idx, phase := range snapshots {
+		phases = append(phases, phase)
+		if phase == corev1.PodFailed {
+			failedSnapshots[idx] = phase
+		}
+	}
+	jr.UpdateStatusCounts(indices.Count(), phases)
+	jr.UpdateSucceededIndices(snapshots)
+	// Only report indices as failed once they ran out of retries.
+	jr.UpdateFailedIndices(failedSnapshots)
+
+	conditions := jr.Status.ManageConditions(s.clock)
+	switch {
+	case deadlineExceeded:
+		conditions.MarkFailed("DeadlineExceeded", "JobRun was active longer than maxExecutionTime")
+	case finished == indices.Count() && failed > 0:
+		conditions.MarkFailed("IndicesFailed", fmt.Sprintf("%d indices failed", failed))
+	case finished == indices.Count():
+		conditions.MarkComplete()
+	case running > 0 || finished > 0:
+		conditions.MarkRunning()
+	default:
+		conditions.MarkPending()
+	}
+	conditions.MarkObservedGeneration(jr.Generation)
+	if jr.IsJobRunFinished() {
+		jr.Status.CompletionTime = &metav1.Time{Time: now}
+	}
+
+	updated := original.DeepCopy()
+	updated.Status = jr.Status
+	_, err = s.client.CodeengineV1beta1().JobRuns(jr.Namespace).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	if apierrors.IsNotFound(err) {
+		delete(s.runs, key)
+		return nil
+	}
+	return err
+}
+
+// advanceIndex runs the attempts of the started index up to now and returns the resulting pod phase.
+// Whether and when a failed attempt is retried is decided by the spec of the jobRun.
+func (s *Simulator) advanceIndex(ctx context.Context, jr *v1beta1.JobRun, run *simulatedRun, idx int64, daemon bool, now time.Time) corev1.PodPhase {
+	index := run.indices[idx]
+
+	for index.phase == corev1.PodRunning {
+		end := index.attemptStart.Add(index.result.Duration)
+		if now.Before(end) {
+			break
+		}
+		if !index.result.Failed && !daemon {
+			index.phase = corev1.PodSucceeded
+			run.freed = append(run.freed, end)
+			break
+		}
+
+		exitCode := index.result.exitCode()
+		decision := jr.Spec.JobDefinitionSpec.DecideRetry(ctx, v1beta1.RetryHistory{
+			Attempts:   index.attempt + 1,
+			ExitCode:   &exitCode,
+			RunRetries: run.retries(),
+		})
+		if !decision.Retry {
+			index.phase = corev1.PodFailed
+			run.freed = append(run.freed, end)
+			break
+		}
+		index.attempt++
+		index.attemptStart = end.Add(decision.Delay)
+		index.result = s.rules.Attempt(jr, idx, index.attempt)
+		if index.result.Duration <= 0 && daemon {
+			// An attempt must take time, not to restart a daemon forever.
+			index.result.Duration = DefaultAttemptDuration
+		}
+	}
+	if index.phase == corev1.PodRunning && now.Before(index.attemptStart) {
+		// The retry waits for its backoff.
+		return corev1.PodPending
+	}
+	return index.phase
+}
+
+// nextSlot returns the time the next index of the run may start at given its parallelism,
+// or false if all slots are taken by running indices.
+func (r *simulatedRun) nextSlot(start time.Time, parallelism *int64) (time.Time, bool) {
+	if parallelism == nil || int64(len(r.indices)) < *parallelism {
+		return start, true
+	}
+	if len(r.freed) == 0 {
+		return time.Time{}, false
+	}
+	first := 0
+	for i, t := range r.freed {
+		if t.Before(r.freed[first]) {
+			first = i
+		}
+	}
+	at := r.freed[first]
+	r.freed = append(r.freed[:first], r.freed[first+1:]...)
+	return at, true
+}
+
+// retries returns the number of retries of all indices of the run.
+func (r *simulatedRun) retries() int64 {
+	var retries int64
+	for _, index := range r.indices {
+		retries += index.attempt
+	}
+	return retries
+}
+
+// updateIndexStatuses records the simulated attempts of the started indices in jr.Status.Indices.
+func (s *Simulator) updateIndexStatuses(jr *v1beta1.JobRun, run *simulatedRun, snapshots map[int64]corev1.PodPhase, now time.Time) {
+	for idx, index := range run.indices {
+		status := v1beta1.IndexStatus{
+			Index:     idx,
+			Phase:     snapshots[idx],
+			Attempts:  index.attempt + 1,
+			StartTime: &metav1.Time{Time: index.startTime},
+		}
+		if status.Phase == corev1.PodSucceeded || status.Phase == corev1.PodFailed {
+			finish := index.attemptStart.Add(index.result.Duration)
+			if finish.After(now) {
+				// The index was terminated by the deadline of the jobRun.
+				finish = now
+			}
+			status.FinishTime = &metav1.Time{Time: finish}
+			if index.result.Failed || status.Phase == corev1.PodFailed {
+				exitCode := index.result.exitCode()
+				if exitCode == 0 {
+					// The index was terminated by the deadline of the jobRun.
+					exitCode = 1
+				}
+				status.LastExitCode = &exitCode
+				status.Reason = "Error"
+			} else {
+				exitCode := int32(0)
+				status.LastExitCode = &exitCode
+				status.Reason = "Completed"
+			}
+		}
+		jr.SetIndexStatus(status)
+	}
+}
+
+// effectiveSpec returns the defaulted spec of the jobRun including the one inherited from its jobDefinition.
+func (s *Simulator) effectiveSpec(ctx context.Context, jr *v1beta1.JobRun) (*v1beta1.JobDefinitionSpec, error) {
+	jds := jr.Spec.JobDefinitionSpec.DeepCopy()
+	if jr.Spec.RequiresDefaultingFromJobDefinition() {
+		jd, err := s.client.CodeengineV1beta1().JobDefinitions(jr.Namespace).Get(ctx, jr.Spec.JobDefinitionRef, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		if err == nil {
+			merged := v1beta1.MergeJobDefinitionSpec(&jd.Spec, jds)
+			jds = &merged
+		}
+	}
+	// Unset fields get the defaults of a standalone jobRun.
+	spec := v1beta1.JobRunSpec{JobDefinitionSpec: *jds}
+	spec.SetDefaults(ctx)
+	return &spec.JobDefinitionSpec, nil
+}
+
+func (s *Simulator) key(jr *v1beta1.JobRun) types.UID {
+	if jr.UID != "" {
+		return jr.UID
+	}
+	return types.UID(jr.Namespace + "/" + jr.Name)
+}
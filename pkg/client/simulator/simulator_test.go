@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/builder"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/fake"
+)
+
+var epoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type harness struct {
+	t     *testing.T
+	ctx   context.Context
+	clock *clocktesting.FakeClock
+	sim   *Simulator
+	c     *fake.Clientset
+}
+
+func newHarness(t *testing.T, rules Rules, jr *v1beta1.JobRun) *harness {
+	c := fake.NewSimpleClientset(jr)
+	clk := clocktesting.NewFakeClock(epoch)
+	return &harness{t: t, ctx: context.Background(), clock: clk, sim: New(c, clk, rules), c: c}
+}
+
+// syncAt moves the clock to the given offset from epoch, syncs and returns the jobRun.
+func (h *harness) syncAt(offset time.Duration) *v1beta1.JobRun {
+	h.t.Helper()
+	h.clock.SetTime(epoch.Add(offset))
+	if err := h.sim.Sync(h.ctx); err != nil {
+		h.t.Fatalf("Sync: %v", err)
+	}
+	jr, err := h.c.CodeengineV1beta1().JobRuns("ns").Get(h.ctx, "run", metav1.GetOptions{})
+	if err != nil {
+		h.t.Fatalf("Get: %v", err)
+	}
+	return jr
+}
+
+func jobRun() *builder.JobRunBuilder {
+	return builder.NewJobRun("run").Namespace("ns").Image("busybox")
+}
+
+func TestSimulatorCompletes(t *testing.T) {
+	h := newHarness(t, Rules{StartDelay: time.Second}, jobRun().Array("0-2").MustBuild())
+
+	if jr := h.syncAt(0); jr.Phase() != v1beta1.JobRunPhasePending || jr.Status.Pending != 3 {
+		t.Errorf("at 0s: phase %s, %d pending, want Pending with 3 pending", jr.Phase(), jr.Status.Pending)
+	}
+	if jr := h.syncAt(time.Second); jr.Phase() != v1beta1.JobRunPhaseRunning || jr.Status.Running != 3 {
+		t.Errorf("at 1s: phase %s, %d running, want Running with 3 running", jr.Phase(), jr.Status.Running)
+	}
+	jr := h.syncAt(2 * time.Second)
+	if jr.Phase() != v1beta1.JobRunPhaseComplete || jr.Status.Succeeded != 3 {
+		t.Errorf("at 2s: phase %s, %d succeeded, want Complete with 3 succeeded", jr.Phase(), jr.Status.Succeeded)
+	}
+	if got := *jr.Status.SucceededIndices; got != "0-2" {
+		t.Errorf("succeededIndices = %q, want 0-2", got)
+	}
+	if jr.Status.CompletionTime == nil || !jr.Status.CompletionTime.Time.Equal(epoch.Add(2*time.Second)) {
+		t.Errorf("completionTime = %v, want %v", jr.Status.CompletionTime, epoch.Add(2*time.Second))
+	}
+}
+
+func TestSimulatorRetries(t *testing.T) {
+	rules := Rules{Attempt: FailIndices(v1beta1.NewIndexSet(1), 2, time.Second)}
+	h := newHarness(t, rules, jobRun().Array("0-1").Retries(1).MustBuild())
+
+	h.syncAt(0)
+	jr := h.syncAt(5 * time.Second)
+	if jr.Phase() != v1beta1.JobRunPhaseFailed {
+		t.Fatalf("phase = %s, want Failed after index 1 used up its retry", jr.Phase())
+	}
+	if got := *jr.Status.FailedIndices; got != "1" {
+		t.Errorf("failedIndices = %q, want 1", got)
+	}
+	status, _ := jr.GetIndexStatus(1)
+	if status.Attempts != 2 {
+		t.Errorf("index 1 made %d attempts, want 2", status.Attempts)
+	}
+
+	h = newHarness(t, rules, jobRun().Array("0-1").Retries(2).MustBuild())
+	h.syncAt(0)
+	if jr := h.syncAt(5 * time.Second); jr.Phase() != v1beta1.JobRunPhaseComplete {
+		t.Errorf("phase = %s, want Complete once index 1 succeeded in its third attempt", jr.Phase())
+	}
+}
+
+func TestSimulatorBackoff(t *testing.T) {
+	rules := Rules{Attempt: FailIndices(v1beta1.NewIndexSet(0), 1, time.Second)}
+	h := newHarness(t, rules, jobRun().Array("0").Retries(1).Backoff(10*time.Second, time.Minute).MustBuild())
+
+	h.syncAt(0)
+	if jr := h.syncAt(5 * time.Second); jr.Status.Pending != 1 {
+		t.Errorf("at 5s: %d pending, want the retry to wait for its backoff", jr.Status.Pending)
+	}
+	if jr := h.syncAt(11 * time.Second); jr.Status.Running != 1 {
+		t.Errorf("at 11s: %d running, want the retry running after its backoff", jr.Status.Running)
+	}
+	if jr := h.syncAt(12 * time.Second); jr.Phase() != v1beta1.JobRunPhaseComplete {
+		t.Errorf("at 12s: phase %s, want Complete", jr.Phase())
+	}
+}
+
+func TestSimulatorNonRetryableExitCode(t *testing.T) {
+	rules := Rules{Attempt: func(*v1beta1.JobRun, int64, int64) AttemptResult {
+		return AttemptResult{Duration: time.Second, Failed: true, ExitCode: 42}
+	}}
+	h := newHarness(t, rules, jobRun().Array("0").Retries(3).NonRetryableExitCodes(42).MustBuild())
+
+	h.syncAt(0)
+	jr := h.syncAt(time.Second)
+	if jr.Phase() != v1beta1.JobRunPhaseFailed {
+		t.Fatalf("phase = %s, want Failed without retries", jr.Phase())
+	}
+	if status, _ := jr.GetIndexStatus(0); status.Attempts != 1 || *status.LastExitCode != 42 {
+		t.Errorf("index 0: %d attempts, exit code %d, want 1 attempt with exit code 42", status.Attempts, *status.LastExitCode)
+	}
+}
+
+func TestSimulatorParallelism(t *testing.T) {
+	h := newHarness(t, Rules{}, jobRun().Array("0-4").Parallelism(2).MustBuild())
+
+	jr := h.syncAt(0)
+	if jr.Status.Running != 2 || jr.Status.Throttled != 3 || jr.Status.Requested != 0 {
+		t.Errorf("at 0s: %d running, %d throttled, %d requested, want 2, 3 and 0",
+			jr.Status.Running, jr.Status.Throttled, jr.Status.Requested)
+	}
+	jr = h.syncAt(2500 * time.Millisecond)
+	if jr.Status.Succeeded != 4 || jr.Status.Running != 1 || jr.Status.Throttled != 0 {
+		t.Errorf("at 2.5s: %d succeeded, %d running, %d throttled, want 4, 1 and 0",
+			jr.Status.Succeeded, jr.Status.Running, jr.Status.Throttled)
+	}
+	jr = h.syncAt(3 * time.Second)
+	if jr.Phase() != v1beta1.JobRunPhaseComplete {
+		t.Fatalf("at 3s: phase %s, want Complete", jr.Phase())
+	}
+	for idx, want := range []time.Duration{0, 0, time.Second, time.Second, 2 * time.Second} {
+		status, _ := jr.GetIndexStatus(int64(idx))
+		if !status.StartTime.Time.Equal(epoch.Add(want)) {
+			t.Errorf("index %d started at %v, want %v", idx, status.StartTime.Time, epoch.Add(want))
+		}
+	}
+}
+
+func TestSimulatorDeadlineFromStartTime(t *testing.T) {
+	jr := jobRun().Array("0").Timeout(10 * time.Second).MustBuild()
+	jr.Status.StartTime = &metav1.Time{Time: epoch.Add(-9 * time.Second)}
+	rules := Rules{Attempt: func(*v1beta1.JobRun, int64, int64) AttemptResult {
+		return AttemptResult{Duration: time.Minute}
+	}}
+	h := newHarness(t, rules, jr)
+
+	if jr := h.syncAt(0); jr.Phase() != v1beta1.JobRunPhaseRunning {
+		t.Fatalf("at 0s: phase %s, want Running", jr.Phase())
+	}
+	jr = h.syncAt(time.Second)
+	if jr.Phase() != v1beta1.JobRunPhaseFailed {
+		t.Fatalf("at 1s: phase %s, want Failed 10s after the preset startTime", jr.Phase())
+	}
+	if got := jr.Status.GetCondition(v1beta1.JobFailed); got == nil || got.Reason != "DeadlineExceeded" {
+		t.Errorf("failed condition = %+v, want reason DeadlineExceeded", got)
+	}
+}
+
+func TestSimulatorDaemon(t *testing.T) {
+	h := newHarness(t, Rules{}, jobRun().Array("0").Daemon().Timeout(time.Minute).MustBuild())
+
+	h.syncAt(0)
+	jr := h.syncAt(30 * time.Second)
+	if jr.Phase() != v1beta1.JobRunPhaseRunning {
+		t.Fatalf("at 30s: phase %s, want a daemon to keep running", jr.Phase())
+	}
+	if status, _ := jr.GetIndexStatus(0); status.Attempts < 30 {
+		t.Errorf("index 0 made %d attempts, want it restarted every second", status.Attempts)
+	}
+	if jr := h.syncAt(time.Minute); jr.Phase() != v1beta1.JobRunPhaseFailed {
+		t.Errorf("at 60s: phase %s, want Failed by maxExecutionTime", jr.Phase())
+	}
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logs streams the logs of the pods that run the indices of a JobRun.
+package logs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+// Options configures which logs Stream collects and where it writes them.
+type Options struct {
+	// Out receives the log lines of all indices, each prefixed with "[<index>/<container>] ".
+	// It is ignored if Dir is set.
+	Out io.Writer
+
+	// Dir is the directory to write one log file per index to, named <jobRun>-<index>.log.
+	Dir string
+
+	// Container limits the logs to the container of the given name, all containers are included if empty,
+	// the init containers first in their order of execution.
+	Container string
+
+	// Follow keeps streaming the logs of running pods and of pods created for retries until ctx is done.
+	Follow bool
+
+	// FailedOnly limits the logs to the failed indices of the jobRun: the indices listed as failed
+	// by its status and the indices with a failed pod, including the pods that retry them.
+	FailedOnly bool
+
+	// Previous includes the logs of previous attempts of an index: pods that were retried
+	// and restarted container instances. Only the latest attempt is included otherwise.
+	Previous bool
+}
+
+// Stream writes the logs of the pods of the jobRun, which are discovered by the
+// LabelJobRun and LabelPodType labels and assigned to indices by the LabelJobIndex label.
+// Only pods whose containers were started are included, with Follow the other ones
+// are included once they start.
+// Without Follow the logs are written ordered by index and attempt.
+func Stream(ctx context.Context, kube kubernetes.Interface, jr *v1beta1.JobRun, opts Options) error {
+	s, err := newStreamer(kube, jr, opts)
+	if err != nil {
+		return err
+	}
+	defer s.close()
+
+	pods, err := s.pods(ctx)
+	if err != nil {
+		return err
+	}
+	if !opts.Follow {
+		for i := range pods {
+			if err := s.streamPod(ctx, &pods[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return s.follow(ctx, pods)
+}
+
+// PodSelector returns the label selector of the pods of the jobRun.
+func PodSelector(jr *v1beta1.JobRun) labels.Selector {
+	return labels.SelectorFromSet(labels.Set{
+		v1beta1.LabelJobRun:  jr.Name,
+		v1beta1.LabelPodType: v1beta1.JobRunType,
+	})
+}
+
+type streamer struct {
+	kube   kubernetes.Interface
+	jobRun *v1beta1.JobRun
+	opts   Options
+	// listVersion is the resource version of the pod list, which the watch of follow starts at.
+	listVersion string
+	// failed holds the indices known to have failed, it's only used by the goroutine that lists and watches the pods.
+	failed map[int64]bool
+
+	mu    sync.Mutex
+	files map[int64]*os.File
+}
+
+func newStreamer(kube kubernetes.Interface, jr *v1beta1.JobRun, opts Options) (*streamer, error) {
+	if opts.Out == nil && opts.Dir == "" {
+		return nil, fmt.Errorf("either Out or Dir must be set")
+	}
+	s := &streamer{kube: kube, jobRun: jr, opts: opts, failed: map[int64]bool{}, files: map[int64]*os.File{}}
+	if opts.FailedOnly {
+		failed, err := jr.GetFailedIndexSet()
+		if err != nil {
+			return nil, err
+		}
+		failed.Each(func(idx int64) bool {
+			s.failed[idx] = true
+			return true
+		})
+	}
+	if opts.Dir != "" {
+		if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *streamer) close() {
+	for _, f := range s.files {
+		f.Close()
+	}
+}
+
+// pods lists the pods to collect logs from, ordered by index and creation.
+func (s *streamer) pods(ctx context.Context) ([]corev1.Pod, error) {
+	list, err := s.kube.CoreV1().Pods(s.jobRun.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: PodSelector(s.jobRun).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.listVersion = list.ResourceVersion
+
+	byIndex := map[int64][]corev1.Pod{}
+	for _, pod := range list.Items {
+		if idx, ok := podIndex(&pod); ok {
+			byIndex[idx] = append(byIndex[idx], pod)
+			s.observe(idx, &pod)
+		}
+	}
+
+	indices := make([]int64, 0, len(byIndex))
+	for idx := range byIndex {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+
+	var pods []corev1.Pod
+	for _, idx := range indices {
+		attempts := byIndex[idx]
+		sort.Slice(attempts, func(i, j int) bool {
+			ti, tj := attempts[i].CreationTimestamp, attempts[j].CreationTimestamp
+			if !ti.Equal(&tj) {
+				return ti.Before(&tj)
+			}
+			return attempts[i].Name < attempts[j].Name
+		})
+		if s.opts.FailedOnly && !s.failed[idx] {
+			continue
+		}
+		if !s.opts.Previous {
+			attempts = attempts[len(attempts)-1:]
+		}
+		for _, pod := range attempts {
+			if hasLogs(&pod) {
+				pods = append(pods, pod)
+			}
+		}
+	}
+	return pods, nil
+}
+
+// observe records the index of a failed pod as failed.
+func (s *streamer) observe(idx int64, pod *corev1.Pod) {
+	if pod.Status.Phase == corev1.PodFailed {
+		s.failed[idx] = true
+	}
+}
+
+// follow streams the given pods and the pods created later on concurrently, until ctx is done.
+func (s *streamer) follow(ctx context.Context, pods []corev1.Pod) error {
+	w, err := s.kube.CoreV1().Pods(s.jobRun.Namespace).Watch(ctx, metav1.ListOptions{
+		LabelSelector:   PodSelector(s.jobRun).String(),
+		ResourceVersion: s.listVersion,
+	})
+	if err != nil {
+		return err
+	}
+	defer w.Stop()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 1)
+	started := map[string]bool{}
+	start := func(pod corev1.Pod) {
+		if started[pod.Name] {
+			return
+		}
+		started[pod.Name] = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.streamPod(ctx, &pod); err != nil && ctx.Err() == nil {
+				select {
+				case errs <- err:
+				default:
+				}
+			}
+		}()
+	}
+	for _, pod := range pods {
+		start(pod)
+	}
+
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			pod, isPod := event.Object.(*corev1.Pod)
+			if !isPod || (event.Type != watch.Added && event.Type != watch.Modified) {
+				continue
+			}
+			idx, ok := podIndex(pod)
+			if !ok {
+				continue
+			}
+			s.observe(idx, pod)
+			if (!s.opts.FailedOnly || s.failed[idx]) && hasLogs(pod) {
+				start(*pod)
+			}
+		}
+	}
+}
+
+// streamPod writes the logs of every selected container of the pod, starting with the init containers.
+func (s *streamer) streamPod(ctx context.Context, pod *corev1.Pod) error {
+	idx, _ := podIndex(pod)
+	containers := append(append([]corev1.Container(nil), pod.Spec.InitContainers...), pod.Spec.Containers...)
+	for _, c := range containers {
+		if s.opts.Container != "" && c.Name != s.opts.Container {
+			continue
+		}
+		if s.opts.Previous && restartCount(pod, c.Name) > 0 {
+			if err := s.streamContainer(ctx, pod, idx, c.Name, true); err != nil {
+				return err
+			}
+		}
+		if err := s.streamContainer(ctx, pod, idx, c.Name, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *streamer) streamContainer(ctx context.Context, pod *corev1.Pod, idx int64, container string, previous bool) error {
+	req := s.kube.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container,
+		Follow:    s.opts.Follow && !previous,
+		Previous:  previous,
+	})
+	stream, err := req.Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("streaming logs of pod %s container %s: %w", pod.Name, container, err)
+	}
+	defer stream.Close()
+
+	if s.opts.Dir != "" {
+		if err := s.writeLine(idx, fmt.Sprintf("==> pod %s container %s <==", pod.Name, container)); err != nil {
+			return err
+		}
+	}
+
+	prefix := fmt.Sprintf("[%d/%s] ", idx, container)
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if s.opts.Dir == "" {
+			line = prefix + line
+		}
+		if err := s.writeLine(idx, line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// writeLine writes a whole line, so lines of concurrent streams don't interleave.
+func (s *streamer) writeLine(idx int64, line string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.opts.Out
+	if s.opts.Dir != "" {
+		f, ok := s.files[idx]
+		if !ok {
+			var err error
+			f, err = os.Create(filepath.Join(s.opts.Dir, fmt.Sprintf("%s-%d.log", s.jobRun.Name, idx)))
+			if err != nil {
+				return err
+			}
+			s.files[idx] = f
+		}
+		w = f
+	}
+	_, err := io.WriteString(w, line+"\n")
+	return err
+}
+
+// hasLogs tells whether the containers of the pod were started, so their logs are available.
+func hasLogs(pod *corev1.Pod) bool {
+	switch pod.Status.Phase {
+	case corev1.PodRunning, corev1.PodSucceeded, corev1.PodFailed:
+		return true
+	}
+	return false
+}
+
+func podIndex(pod *corev1.Pod) (int64, bool) {
+	idx, err := strconv.ParseInt(pod.Labels[v1beta1.LabelJobIndex], 10, 64)
+	return idx, err == nil
+}
+
+func restartCount(pod *corev1.Pod, container string) int32 {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.Name == container {
+				return status.RestartCount
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+)
+
+// The fake clientset returns "fake logs" as the log of every container.
+
+var created = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func testJobRun() *v1beta1.JobRun {
+	return &v1beta1.JobRun{ObjectMeta: metav1.ObjectMeta{Name: "run", Namespace: "ns"}}
+}
+
+func testPod(idx, attempt int64, phase corev1.PodPhase, restarts int32) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      testJobRun().PodName(idx, attempt),
+			Namespace: "ns",
+			Labels: map[string]string{
+				v1beta1.LabelJobRun:   "run",
+				v1beta1.LabelPodType:  v1beta1.JobRunType,
+				v1beta1.LabelJobIndex: strconv.FormatInt(idx, 10),
+			},
+			CreationTimestamp: metav1.NewTime(created.Add(time.Duration(attempt) * time.Minute)),
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}},
+		Status: corev1.PodStatus{
+			Phase:             phase,
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "main", RestartCount: restarts}},
+		},
+	}
+}
+
+// logRequests returns the containers whose logs were requested, with "(previous)" appended for previous instances.
+func logRequests(kube *fake.Clientset) []string {
+	var requests []string
+	for _, action := range kube.Actions() {
+		if action.GetSubresource() != "log" {
+			continue
+		}
+		opts := action.(k8stesting.GenericAction).GetValue().(*corev1.PodLogOptions)
+		request := opts.Container
+		if opts.Previous {
+			request += " (previous)"
+		}
+		requests = append(requests, request)
+	}
+	return requests
+}
+
+func TestStreamPrefixesLines(t *testing.T) {
+	kube := fake.NewSimpleClientset(
+		testPod(1, 0, corev1.PodRunning, 0),
+		testPod(0, 0, corev1.PodSucceeded, 0),
+	)
+	var out bytes.Buffer
+	if err := Stream(context.Background(), kube, testJobRun(), Options{Out: &out}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if want := "[0/main] fake logs\n[1/main] fake logs\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestStreamSkipsPodsWithoutStartedContainers(t *testing.T) {
+	kube := fake.NewSimpleClientset(
+		testPod(0, 0, corev1.PodSucceeded, 0),
+		testPod(1, 0, corev1.PodPending, 0),
+	)
+	var out bytes.Buffer
+	if err := Stream(context.Background(), kube, testJobRun(), Options{Out: &out}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if want := "[0/main] fake logs\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+	if got := len(logRequests(kube)); got != 1 {
+		t.Errorf("requested %d logs, want only the one of the started pod", got)
+	}
+}
+
+func TestStreamFailedOnly(t *testing.T) {
+	jr := testJobRun()
+	failed := "1"
+	jr.Status.FailedIndices = &failed
+	kube := fake.NewSimpleClientset(
+		testPod(0, 0, corev1.PodSucceeded, 0),
+		testPod(1, 0, corev1.PodFailed, 0),
+		// Index 2 failed but its status isn't updated yet, index 3 is retried after a failure.
+		testPod(2, 0, corev1.PodFailed, 0),
+		testPod(3, 0, corev1.PodFailed, 0),
+		testPod(3, 1, corev1.PodRunning, 0),
+	)
+	var out bytes.Buffer
+	if err := Stream(context.Background(), kube, jr, Options{Out: &out, FailedOnly: true, Previous: true}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	want := "[1/main] fake logs\n[2/main] fake logs\n[3/main] fake logs\n[3/main] fake logs\n"
+	if out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestStreamPrevious(t *testing.T) {
+	pods := []runtime.Object{
+		testPod(0, 0, corev1.PodFailed, 0),
+		testPod(0, 1, corev1.PodRunning, 2),
+	}
+
+	kube := fake.NewSimpleClientset(pods...)
+	var out bytes.Buffer
+	if err := Stream(context.Background(), kube, testJobRun(), Options{Out: &out}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if got, want := strings.Join(logRequests(kube), ","), "main"; got != want {
+		t.Errorf("without Previous requested logs %q, want %q of the latest attempt", got, want)
+	}
+
+	kube = fake.NewSimpleClientset(pods...)
+	if err := Stream(context.Background(), kube, testJobRun(), Options{Out: &out, Previous: true}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if got, want := strings.Join(logRequests(kube), ","), "main,main (previous),main"; got != want {
+		t.Errorf("with Previous requested logs %q, want %q", got, want)
+	}
+}
+
+// withInitContainers adds init containers and a sidecar to the pod.
+func withInitContainers(pod *corev1.Pod, restarts int32) *corev1.Pod {
+	pod.Spec.InitContainers = []corev1.Container{{Name: "setup"}, {Name: "download"}}
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "proxy"})
+	pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{Name: "setup"}, {Name: "download", RestartCount: restarts}}
+	return pod
+}
+
+func TestStreamInitContainers(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{{
+		name: "all containers",
+		want: "setup,download,main,proxy",
+	}, {
+		name: "init container",
+		opts: Options{Container: "download"},
+		want: "download",
+	}, {
+		name: "previous",
+		opts: Options{Previous: true},
+		want: "setup,download (previous),download,main,proxy",
+	}}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kube := fake.NewSimpleClientset(withInitContainers(testPod(0, 0, corev1.PodSucceeded, 0), 1))
+			var out bytes.Buffer
+			test.opts.Out = &out
+			if err := Stream(context.Background(), kube, testJobRun(), test.opts); err != nil {
+				t.Fatalf("Stream: %v", err)
+			}
+			if got := strings.Join(logRequests(kube), ","); got != test.want {
+				t.Errorf("requested logs %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestStreamDirInitContainers(t *testing.T) {
+	kube := fake.NewSimpleClientset(withInitContainers(testPod(0, 0, corev1.PodFailed, 0), 0))
+	dir := t.TempDir()
+	if err := Stream(context.Background(), kube, testJobRun(), Options{Dir: dir}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "run-0.log"))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := "==> pod run-0-0 container setup <==\nfake logs\n" +
+		"==> pod run-0-0 container download <==\nfake logs\n" +
+		"==> pod run-0-0 container main <==\nfake logs\n" +
+		"==> pod run-0-0 container proxy <==\nfake logs\n"
+	if string(data) != want {
+		t.Errorf("run-0.log = %q, want %q", data, want)
+	}
+}
+
+func TestStreamDir(t *testing.T) {
+	kube := fake.NewSimpleClientset(
+		testPod(0, 0, corev1.PodSucceeded, 0),
+		testPod(1, 0, corev1.PodFailed, 0),
+		testPod(1, 1, corev1.PodSucceeded, 0),
+	)
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := Stream(context.Background(), kube, testJobRun(), Options{Dir: dir, Previous: true}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	want := map[string]string{
+		"run-0.log": "==> pod run-0-0 container main <==\nfake logs\n",
+		"run-1.log": "==> pod run-1-0 container main <==\nfake logs\n==> pod run-1-1 container main <==\nfake logs\n",
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("wrote %d files, want %d", len(entries), len(want))
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("reading %s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for the concurrent streams of Follow.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// follow runs Stream with Follow until the output has the given number of lines, applying change once the watch is established.
+func follow(t *testing.T, kube *fake.Clientset, opts Options, change func(), lines int) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	watching := make(chan struct{})
+	kube.PrependWatchReactor("pods", func(k8stesting.Action) (bool, watch.Interface, error) {
+		defer close(watching)
+		return false, nil, nil
+	})
+
+	out := &syncBuffer{}
+	opts.Out, opts.Follow = out, true
+	done := make(chan error, 1)
+	go func() { done <- Stream(ctx, kube, testJobRun(), opts) }()
+
+	<-watching
+	change()
+	for strings.Count(out.String(), "\n") < lines && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	return out.String()
+}
+
+func TestFollowStartsPendingPods(t *testing.T) {
+	pending := testPod(0, 0, corev1.PodPending, 0)
+	kube := fake.NewSimpleClientset(pending)
+
+	got := follow(t, kube, Options{}, func() {
+		running := pending.DeepCopy()
+		running.Status.Phase = corev1.PodRunning
+		if _, err := kube.CoreV1().Pods("ns").UpdateStatus(context.Background(), running, metav1.UpdateOptions{}); err != nil {
+			t.Errorf("UpdateStatus: %v", err)
+		}
+	}, 1)
+	if want := "[0/main] fake logs\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestFollowFailedOnly(t *testing.T) {
+	running := testPod(0, 0, corev1.PodRunning, 0)
+	kube := fake.NewSimpleClientset(running, testPod(1, 0, corev1.PodRunning, 0))
+
+	got := follow(t, kube, Options{FailedOnly: true}, func() {
+		ctx := context.Background()
+		failed := running.DeepCopy()
+		failed.Status.Phase = corev1.PodFailed
+		if _, err := kube.CoreV1().Pods("ns").UpdateStatus(ctx, failed, metav1.UpdateOptions{}); err != nil {
+			t.Errorf("UpdateStatus: %v", err)
+		}
+		if _, err := kube.CoreV1().Pods("ns").Create(ctx, testPod(0, 1, corev1.PodRunning, 0), metav1.CreateOptions{}); err != nil {
+			t.Errorf("Create: %v", err)
+		}
+	}, 2)
+	if want := "[0/main] fake logs\n[0/main] fake logs\n"; got != want {
+		t.Errorf("output = %q, want the failed pod and its retry: %q", got, want)
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package queue submits JobRuns from a client-side queue, ordered by priority
+// and limited to a number of concurrently active JobRuns per namespace.
+package queue
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	clientset "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned"
+	informers "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/informers/externalversions/codeengine/v1beta1"
+	listers "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/listers/codeengine/v1beta1"
+)
+
+// listRetryDelay is the delay before the queue tries again to submit to a namespace
+// whose jobRuns could not be listed.
+const listRetryDelay = time.Second
+
+// Options configures a Queue.
+type Options struct {
+	// MaxActive is the number of unfinished jobRuns per namespace, including the ones not
+	// submitted by the queue, beyond which no further jobRun is submitted. 0 means no limit.
+	MaxActive int
+
+	// OnSubmit is called after every attempt to create a jobRun, with the created jobRun,
+	// or with the queued jobRun and the error of the attempt. A jobRun which failed to be
+	// created is dropped.
+	OnSubmit func(jr *v1beta1.JobRun, err error)
+}
+
+// Queue holds jobRuns locally and submits them once their namespace has fewer than MaxActive
+// unfinished jobRuns, higher priorities first and jobRuns of the same priority in the order they were added.
+// Unfinished jobRuns are counted from the jobRun informer, which must be started by the caller.
+type Queue struct {
+	client clientset.Interface
+	lister listers.JobRunLister
+	synced cache.InformerSynced
+	opts   Options
+
+	mu sync.Mutex
+	// pending holds the queued jobRuns by namespace.
+	pending map[string]*jobRunHeap
+	// submitted holds the names of the jobRuns created but not yet seen by the informer, by namespace.
+	submitted map[string]map[string]bool
+	seq       int64
+	wake      chan struct{}
+}
+
+// New returns a queue which submits jobRuns with client and tracks them with informer.
+func New(client clientset.Interface, informer informers.JobRunInformer, opts Options) *Queue {
+	q := &Queue{
+		client:    client,
+		lister:    informer.Lister(),
+		synced:    informer.Informer().HasSynced,
+		opts:      opts,
+		pending:   map[string]*jobRunHeap{},
+		submitted: map[string]map[string]bool{},
+		wake:      make(chan struct{}, 1),
+	}
+	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { q.notify() },
+		UpdateFunc: func(interface{}, interface{}) { q.notify() },
+		DeleteFunc: func(interface{}) { q.notify() },
+	})
+	return q
+}
+
+// Add queues a copy of the jobRun for submission.
+// The jobRun must have a namespace and either a name or a generateName.
+func (q *Queue) Add(jr *v1beta1.JobRun) error {
+	if jr.Namespace == "" {
+		return fmt.Errorf("jobRun %q has no namespace", jr.Name)
+	}
+	if jr.Name == "" && jr.GenerateName == "" {
+		return fmt.Errorf("jobRun has neither name nor generateName")
+	}
+
+	q.mu.Lock()
+	h, ok := q.pending[jr.Namespace]
+	if !ok {
+		h = &jobRunHeap{}
+		q.pending[jr.Namespace] = h
+	}
+	q.seq++
+	heap.Push(h, queuedJobRun{jobRun: jr.DeepCopy(), seq: q.seq})
+	q.mu.Unlock()
+
+	q.notify()
+	return nil
+}
+
+// Len returns the number of jobRuns waiting for submission.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := 0
+	for _, h := range q.pending {
+		n += h.Len()
+	}
+	return n
+}
+
+// Run submits the queued jobRuns until ctx is done, once the informer has synced.
+func (q *Queue) Run(ctx context.Context) error {
+	if !cache.WaitForCacheSync(ctx.Done(), q.synced) {
+		return fmt.Errorf("waiting for the jobRun informer to sync: %w", ctx.Err())
+	}
+	for {
+		var retry <-chan time.Time
+		if !q.submit(ctx) {
+			retry = time.After(listRetryDelay)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-q.wake:
+		case <-retry:
+		}
+	}
+}
+
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// submit creates the queued jobRuns each namespace has room for.
+// It returns false if the jobRuns of a namespace could not be listed, whose queued jobRuns
+// are kept until the next attempt.
+func (q *Queue) submit(ctx context.Context) bool {
+	ok := true
+	q.mu.Lock()
+	var batch []*v1beta1.JobRun
+	for ns, h := range q.pending {
+		room := h.Len()
+		if q.opts.MaxActive > 0 {
+			active, err := q.active(ns)
+			if err != nil {
+				ok = false
+				continue
+			}
+			room = q.opts.MaxActive - active
+		}
+		for ; room > 0 && h.Len() > 0; room-- {
+			jr := heap.Pop(h).(queuedJobRun).jobRun
+			batch = append(batch, jr)
+			if jr.Name != "" {
+				q.markSubmitted(jr.Namespace, jr.Name)
+			}
+		}
+		if h.Len() == 0 {
+			delete(q.pending, ns)
+		}
+	}
+	q.mu.Unlock()
+
+	for _, jr := range batch {
+		created, err := q.client.CodeengineV1beta1().JobRuns(jr.Namespace).Create(ctx, jr, metav1.CreateOptions{})
+		q.mu.Lock()
+		switch {
+		case err == nil:
+			q.markSubmitted(created.Namespace, created.Name)
+		case jr.Name != "" && !apierrors.IsAlreadyExists(err):
+			delete(q.submitted[jr.Namespace], jr.Name)
+		}
+		q.mu.Unlock()
+		if q.opts.OnSubmit != nil {
+			if err != nil {
+				created = jr
+			}
+			q.opts.OnSubmit(created, err)
+		}
+	}
+	if len(batch) > 0 {
+		// Room freed by failed submissions is used by the next round.
+		q.notify()
+	}
+	return ok
+}
+
+// active returns the number of unfinished jobRuns of the namespace, including the ones submitted
+// but not yet seen by the informer. It forgets the submitted jobRuns the informer has seen.
+func (q *Queue) active(ns string) (int, error) {
+	jobRuns, err := q.lister.JobRuns(ns).List(labels.Everything())
+	if err != nil {
+		return 0, err
+	}
+	active := 0
+	for _, jr := range jobRuns {
+		delete(q.submitted[ns], jr.Name)
+		if !jr.IsJobRunFinished() {
+			active++
+		}
+	}
+	return active + len(q.submitted[ns]), nil
+}
+
+func (q *Queue) markSubmitted(ns, name string) {
+	if q.submitted[ns] == nil {
+		q.submitted[ns] = map[string]bool{}
+	}
+	q.submitted[ns][name] = true
+}
+
+type queuedJobRun struct {
+	jobRun *v1beta1.JobRun
+	seq    int64
+}
+
+// jobRunHeap orders jobRuns by descending priority and ascending sequence number.
+type jobRunHeap []queuedJobRun
+
+func (h jobRunHeap) Len() int { return len(h) }
+
+func (h jobRunHeap) Less(i, j int) bool {
+	if pi, pj := h[i].jobRun.GetPriority(), h[j].jobRun.GetPriority(); pi != pj {
+		return pi > pj
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobRunHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *jobRunHeap) Push(x interface{}) { *h = append(*h, x.(queuedJobRun)) }
+
+func (h *jobRunHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Knative Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package queue
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/utils/clock"
+	"k8s.io/utils/pointer"
+
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/apis/codeengine/v1beta1"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/clientset/versioned/fake"
+	"github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/informers/externalversions"
+	listers "github.com/rafalbigaj/code-engine-batch-job-client/pkg/client/listers/codeengine/v1beta1"
+)
+
+type harness struct {
+	t      *testing.T
+	q      *Queue
+	client *fake.Clientset
+	// seen holds the jobRuns the informer has seen.
+	seen      cache.Indexer
+	submitted []string
+	errs      []error
+}
+
+// newHarness returns a queue whose informer watches a separate clientset, so the jobRuns it
+// creates stay unseen until a test adds them to the informer with see.
+func newHarness(t *testing.T, maxActive int, seen ...*v1beta1.JobRun) *harness {
+	informer := externalversions.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).Codeengine().V1beta1().JobRuns()
+	h := &harness{t: t, client: fake.NewSimpleClientset(), seen: informer.Informer().GetIndexer()}
+	h.q = New(h.client, informer, Options{
+		MaxActive: maxActive,
+		OnSubmit: func(jr *v1beta1.JobRun, err error) {
+			h.submitted = append(h.submitted, jr.Namespace+"/"+jr.Name)
+			h.errs = append(h.errs, err)
+		},
+	})
+	h.see(seen...)
+	return h
+}
+
+func (h *harness) add(jobRuns ...*v1beta1.JobRun) {
+	for _, jr := range jobRuns {
+		if err := h.q.Add(jr); err != nil {
+			h.t.Fatalf("Add: %v", err)
+		}
+	}
+}
+
+func (h *harness) see(jobRuns ...*v1beta1.JobRun) {
+	for _, jr := range jobRuns {
+		if err := h.seen.Update(jr); err != nil {
+			h.t.Fatalf("updating the informer: %v", err)
+		}
+	}
+}
+
+// submit runs one round of submissions and returns the jobRuns submitted by it.
+func (h *harness) submit() []string {
+	n := len(h.submitted)
+	if !h.q.submit(context.Background()) {
+		h.t.Fatal("submit failed to list the jobRuns")
+	}
+	return h.submitted[n:]
+}
+
+func jobRun(ns, name string, priority int32) *v1beta1.JobRun {
+	return &v1beta1.JobRun{
+		ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name},
+		Spec:       v1beta1.JobRunSpec{Priority: pointer.Int32(priority)},
+	}
+}
+
+func finished(jr *v1beta1.JobRun) *v1beta1.JobRun {
+	jr = jr.DeepCopy()
+	jr.Status.ManageConditions(clock.RealClock{}).MarkComplete()
+	return jr
+}
+
+func TestSubmitByPriority(t *testing.T) {
+	h := newHarness(t, 0)
+	h.add(
+		jobRun("ns", "low", -1),
+		jobRun("ns", "default-1", 0),
+		jobRun("ns", "high-1", 5),
+		jobRun("ns", "default-2", 0),
+		jobRun("ns", "high-2", 5),
+	)
+
+	got := h.submit()
+	want := []string{"ns/high-1", "ns/high-2", "ns/default-1", "ns/default-2", "ns/low"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("submitted %v, want %v", got, want)
+	}
+	if n := h.q.Len(); n != 0 {
+		t.Errorf("Len() = %d, want 0", n)
+	}
+}
+
+func TestSubmitLimitsActiveJobRunsPerNamespace(t *testing.T) {
+	running := jobRun("ns", "running", 0)
+	h := newHarness(t, 2, running, finished(jobRun("ns", "done", 0)), jobRun("other", "running", 0))
+	h.add(
+		jobRun("ns", "a", 0),
+		jobRun("ns", "b", 0),
+		jobRun("ns", "c", 0),
+		jobRun("other", "d", 0),
+	)
+
+	// The finished jobRun doesn't count, the one of the other namespace counts for its own.
+	got := h.submit()
+	if want := []string{"ns/a", "other/d"}; !sameElements(got, want) {
+		t.Fatalf("submitted %v, want %v", got, want)
+	}
+	if got := h.submit(); len(got) != 0 {
+		t.Fatalf("submitted %v beyond the limit", got)
+	}
+
+	h.see(finished(running))
+	if got, want := h.submit(), []string{"ns/b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("submitted %v after a jobRun finished, want %v", got, want)
+	}
+	if n := h.q.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+}
+
+func TestSubmittedJobRunsCountUntilSeen(t *testing.T) {
+	h := newHarness(t, 1)
+	h.add(jobRun("ns", "a", 0), jobRun("ns", "b", 0))
+
+	if got, want := h.submit(), []string{"ns/a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("submitted %v, want %v", got, want)
+	}
+	// a was created but the informer hasn't seen it yet.
+	if got := h.submit(); len(got) != 0 {
+		t.Fatalf("submitted %v while a is unseen", got)
+	}
+
+	a, err := h.client.CodeengineV1beta1().JobRuns("ns").Get(context.Background(), "a", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting a: %v", err)
+	}
+	h.see(a)
+	if got := h.submit(); len(got) != 0 {
+		t.Fatalf("submitted %v while a is running", got)
+	}
+	if n := len(h.q.submitted["ns"]); n != 0 {
+		t.Errorf("%d jobRuns are still counted as unseen, want none", n)
+	}
+
+	h.see(finished(a))
+	if got, want := h.submit(), []string{"ns/b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("submitted %v once a finished, want %v", got, want)
+	}
+}
+
+func TestSubmitReportsFailedCreate(t *testing.T) {
+	h := newHarness(t, 1)
+	h.client.PrependReactor("create", "jobruns", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("unavailable")
+	})
+	h.add(jobRun("ns", "a", 0), jobRun("ns", "b", 0))
+
+	if got, want := h.submit(), []string{"ns/a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("submitted %v, want %v", got, want)
+	}
+	if h.errs[0] == nil {
+		t.Fatal("OnSubmit got no error")
+	}
+	// The failed jobRun is dropped and frees its slot.
+	if got, want := h.submit(), []string{"ns/b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("submitted %v after a failed, want %v", got, want)
+	}
+}
+
+type failingLister struct {
+	listers.JobRunLister
+}
+
+func (failingLister) JobRuns(string) listers.JobRunNamespaceLister {
+	return failingNamespaceLister{}
+}
+
+type failingNamespaceLister struct {
+	listers.JobRunNamespaceLister
+}
+
+func (failingNamespaceLister) List(labels.Selector) ([]*v1beta1.JobRun, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestSubmitKeepsJobRunsWhenListingFails(t *testing.T) {
+	h := newHarness(t, 1)
+	lister := h.q.lister
+	h.q.lister = failingLister{}
+	h.add(jobRun("ns", "a", 0))
+
+	if h.q.submit(context.Background()) {
+		t.Fatal("submit succeeded although the jobRuns could not be listed")
+	}
+	if n := h.q.Len(); n != 1 {
+		t.Fatalf("Len() = %d, want 1", n)
+	}
+
+	h.q.lister = lister
+	if got, want := h.submit(), []string{"ns/a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("submitted %v, want %v", got, want)
+	}
+}
+
+func sameElements(a, b []string) bool {
+	count := map[string]int{}
+	for _, s := range a {
+		count[s]++
+	}
+	for _, s := range b {
+		count[s]--
+	}
+	for _, n := range count {
+		if n != 0 {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}